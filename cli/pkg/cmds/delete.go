@@ -0,0 +1,34 @@
+package cmds
+
+import (
+	"github.com/acorn-io/aml/pkg/edit"
+	"github.com/acorn-io/cmd"
+	"github.com/spf13/cobra"
+)
+
+type Delete struct {
+	aml *AML
+}
+
+func NewDelete(aml *AML) *cobra.Command {
+	return cmd.Command(&Delete{aml: aml}, cobra.Command{
+		Use:           "delete [flags] FILE PATH",
+		Short:         "Delete the field or list element at PATH, writing the output to the source file if changed",
+		Args:          cobra.ExactArgs(2),
+		SilenceErrors: true,
+	})
+}
+
+func (d *Delete) Run(cmd *cobra.Command, args []string) error {
+	data, err := readSource(args[0])
+	if err != nil {
+		return err
+	}
+
+	newData, err := edit.Delete(data, args[1])
+	if err != nil {
+		return err
+	}
+
+	return writeSource(args[0], data, newData)
+}
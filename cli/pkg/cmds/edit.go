@@ -0,0 +1,40 @@
+package cmds
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// readSource reads the file to edit, or stdin if the filename is -
+func readSource(filename string) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if filename == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", filename, err)
+	}
+	return data, nil
+}
+
+// writeSource writes the edited file back if it changed, or to stdout if the filename is -
+func writeSource(filename string, data, newData []byte) error {
+	if filename == "-" {
+		_, err := os.Stdout.Write(newData)
+		return err
+	}
+	if bytes.Equal(data, newData) {
+		return nil
+	}
+	if err := os.WriteFile(filename, newData, 0644); err != nil {
+		return fmt.Errorf("writing file %s: %w", filename, err)
+	}
+	return nil
+}
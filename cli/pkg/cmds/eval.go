@@ -23,6 +23,7 @@ type Eval struct {
 	PrintArgs   bool   `usage:"Evaluate the file and print args description"`
 	PrintSchema bool   `usage:"Evaluate the file as schema and print schema description"`
 	SchemaFile  string `usage:"Validate result against schema file"`
+	Validation  string `usage:"Mode to validate against the schema file: strict, defaults-only or report-missing (default fills in defaults and fails on missing required fields)"`
 }
 
 func NewEval(aml *AML) *cobra.Command {
@@ -39,6 +40,10 @@ func (e *Eval) Customize(cmd *cobra.Command) {
 }
 
 func (e *Eval) Run(cmd *cobra.Command, args []string) error {
+	if e.Validation != "" && e.SchemaFile == "" {
+		return fmt.Errorf("--validation requires --schema-file")
+	}
+
 	filename := args[0]
 	args = args[1:]
 
@@ -77,9 +82,15 @@ func (e *Eval) Run(cmd *cobra.Command, args []string) error {
 		out = &val
 	}
 
+	mode, err := value.ParseValidationMode(e.Validation)
+	if err != nil {
+		return err
+	}
+
 	err = aml.Unmarshal(data, out, aml.DecoderOption{
 		Schema:           schemaInput,
 		SchemaSourceName: e.SchemaFile,
+		ValidationMode:   mode,
 		SourceName:       filename,
 		Args:             argsData,
 		Profiles:         profiles,
@@ -0,0 +1,37 @@
+package cmds
+
+import (
+	"fmt"
+
+	"github.com/acorn-io/aml/pkg/edit"
+	"github.com/acorn-io/cmd"
+	"github.com/spf13/cobra"
+)
+
+type Get struct {
+	aml *AML
+}
+
+func NewGet(aml *AML) *cobra.Command {
+	return cmd.Command(&Get{aml: aml}, cobra.Command{
+		Use:           "get [flags] FILE PATH",
+		Short:         "Print the expression of the field at PATH, such as containers.web.image",
+		Args:          cobra.ExactArgs(2),
+		SilenceErrors: true,
+	})
+}
+
+func (g *Get) Run(cmd *cobra.Command, args []string) error {
+	data, err := readSource(args[0])
+	if err != nil {
+		return err
+	}
+
+	expr, err := edit.Get(data, args[1])
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(cmd.OutOrStdout(), expr)
+	return err
+}
@@ -0,0 +1,62 @@
+package cmds
+
+import (
+	"fmt"
+
+	"github.com/acorn-io/aml"
+	"github.com/acorn-io/cmd"
+	"github.com/spf13/cobra"
+)
+
+type Migrate struct {
+	aml *AML
+
+	Migrations string `usage:"File declaring the schema versions and migrations"`
+	From       string `usage:"Version of the document, detected if not set"`
+	To         string `usage:"Version to migrate to, the newest version if not set"`
+	Reformat   bool   `usage:"Replace the whole file, dropping its comments, if the changes can not be made in place"`
+}
+
+func NewMigrate(aml *AML) *cobra.Command {
+	return cmd.Command(&Migrate{aml: aml}, cobra.Command{
+		Use:           "migrate [flags] FILE",
+		Short:         "Migrate FILE to a newer version of its schema, writing the output to the source file if changed",
+		Args:          cobra.ExactArgs(1),
+		SilenceErrors: true,
+	})
+}
+
+func (m *Migrate) Run(cmd *cobra.Command, args []string) error {
+	if m.Migrations == "" {
+		return fmt.Errorf("--migrations is required")
+	}
+
+	data, err := readSource(args[0])
+	if err != nil {
+		return err
+	}
+
+	migrations, err := aml.Open(m.Migrations)
+	if err != nil {
+		return err
+	}
+	defer migrations.Close()
+
+	newData, from, err := aml.Migrate(data, aml.MigrateOption{
+		Migrations:           migrations,
+		MigrationsSourceName: m.Migrations,
+		SourceName:           args[0],
+		From:                 m.From,
+		To:                   m.To,
+		Reformat:             m.Reformat,
+		Context:              cmd.Context(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if args[0] != "-" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "migrated %s from version %s\n", args[0], from)
+	}
+	return writeSource(args[0], data, newData)
+}
@@ -23,6 +23,10 @@ type AML struct {
 func (a *AML) Customize(cmd *cobra.Command) {
 	cmd.AddCommand(NewEval(a))
 	cmd.AddCommand(NewFmt(a))
+	cmd.AddCommand(NewGet(a))
+	cmd.AddCommand(NewSet(a))
+	cmd.AddCommand(NewDelete(a))
+	cmd.AddCommand(NewMigrate(a))
 }
 
 func (a *AML) Run(cmd *cobra.Command, args []string) error {
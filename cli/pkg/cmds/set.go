@@ -0,0 +1,49 @@
+package cmds
+
+import (
+	"strconv"
+
+	"github.com/acorn-io/aml/pkg/edit"
+	"github.com/acorn-io/cmd"
+	"github.com/spf13/cobra"
+)
+
+type Set struct {
+	aml *AML
+
+	String bool `usage:"Set the value to VALUE as a string rather than parsing it as an expression"`
+	Append bool `usage:"Append VALUE to the list at PATH rather than replacing it"`
+}
+
+func NewSet(aml *AML) *cobra.Command {
+	return cmd.Command(&Set{aml: aml}, cobra.Command{
+		Use:           "set [flags] FILE PATH VALUE",
+		Short:         "Set the field at PATH to the expression VALUE, writing the output to the source file if changed",
+		Args:          cobra.ExactArgs(3),
+		SilenceErrors: true,
+	})
+}
+
+func (s *Set) Run(cmd *cobra.Command, args []string) error {
+	filename, path, expr := args[0], args[1], args[2]
+	if s.String {
+		expr = strconv.Quote(expr)
+	}
+
+	data, err := readSource(filename)
+	if err != nil {
+		return err
+	}
+
+	var newData []byte
+	if s.Append {
+		newData, err = edit.Append(data, path, expr)
+	} else {
+		newData, err = edit.Set(data, path, expr)
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeSource(filename, data, newData)
+}
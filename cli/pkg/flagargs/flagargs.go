@@ -75,11 +75,11 @@ func New(argsFile, filename string, profiles value.Names, args []value.ObjectSch
 			continue
 		}
 		if field.Schema.TargetKind() == value.BoolKind {
-			flag.Bool = flagSet.Bool(field.Key, false, field.Description)
+			flag.Bool = flagSet.Bool(field.Key, false, usage(field))
 		} else if field.Schema.TargetKind() == value.ArrayKind {
-			flag.StringSlice = flagSet.StringSlice(field.Key, nil, field.Description)
+			flag.StringSlice = flagSet.StringSlice(field.Key, nil, usage(field))
 		} else {
-			flag.String = flagSet.String(field.Key, "", field.Description)
+			flag.String = flagSet.String(field.Key, "", usage(field))
 		}
 		fieldFlags[field.Key] = flag
 	}
@@ -92,10 +92,36 @@ func New(argsFile, filename string, profiles value.Names, args []value.ObjectSch
 	}
 }
 
+// usage returns the help text of the flag for field, including the tags of its doc
+// comment
+func usage(field value.ObjectSchemaField) string {
+	var notes []string
+	if field.Deprecated {
+		if field.DeprecatedMessage != "" {
+			notes = append(notes, "deprecated: "+field.DeprecatedMessage)
+		} else {
+			notes = append(notes, "deprecated")
+		}
+	}
+	if field.Since != "" {
+		notes = append(notes, "since "+field.Since)
+	}
+	for _, example := range field.Examples {
+		notes = append(notes, "example: "+example)
+	}
+	if len(notes) == 0 {
+		return field.Description
+	}
+	if field.Description == "" {
+		return "(" + strings.Join(notes, ", ") + ")"
+	}
+	return field.Description + " (" + strings.Join(notes, ", ") + ")"
+}
+
 func parseValue(v string, kind value.Kind) (any, error) {
 	if !strings.HasPrefix(v, "@") {
 		if kind == value.NumberKind {
-			return value.Number(v), nil
+			return value.ParseNumber(v), nil
 		}
 		return v, nil
 	}
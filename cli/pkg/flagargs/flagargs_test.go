@@ -74,3 +74,22 @@ func TestHelp(t *testing.T) {
 	autogold.Expect("pflag: help requested").Equal(t, err.Error())
 	autogold.ExpectFile(t, autogold.Raw(buffer.String()))
 }
+
+func TestHelpDocTags(t *testing.T) {
+	var file value.FuncSchema
+
+	data, err := os.ReadFile("testdata/TestHelpDocTags/input.acorn")
+	require.NoError(t, err)
+
+	err = aml.Unmarshal(data, &file)
+	require.NoError(t, err)
+
+	buffer := &bytes.Buffer{}
+
+	flags := New("", "testdata/TestHelpDocTags/input.acorn", file.ProfileNames, file.Args)
+	flags.FlagSet.SetOutput(buffer)
+
+	_, _, err = flags.Parse([]string{"--help"})
+	autogold.Expect("pflag: help requested").Equal(t, err.Error())
+	autogold.ExpectFile(t, autogold.Raw(buffer.String()))
+}
@@ -24,9 +24,11 @@ type DecoderOption struct {
 	SchemaSourceName string
 	Schema           io.Reader
 	SchemaValue      value.Value
-	Globals          map[string]any
-	GlobalsLookup    eval.ScopeFunc
-	Context          context.Context
+	// ValidationMode selects how the value is validated against Schema or SchemaValue
+	ValidationMode value.ValidationMode
+	Globals        map[string]any
+	GlobalsLookup  eval.ScopeFunc
+	Context        context.Context
 }
 
 func (o DecoderOption) Complete() DecoderOption {
@@ -69,6 +71,9 @@ func (o DecoderOptions) Merge() (result DecoderOption) {
 		if opt.SchemaValue != nil {
 			result.SchemaValue = opt.SchemaValue
 		}
+		if opt.ValidationMode != "" {
+			result.ValidationMode = opt.ValidationMode
+		}
 		if len(opt.Globals) > 0 && result.Globals == nil {
 			result.Globals = map[string]any{}
 		}
@@ -100,6 +105,8 @@ func (d *Decoder) processSchema(ctx context.Context, data value.Value) (value.Va
 		return nil, err
 	}
 
+	ctx = value.WithValidationMode(ctx, d.opts.ValidationMode)
+
 	if d.opts.SchemaValue != nil {
 		return value.Validate(ctx, d.opts.SchemaValue, data)
 	}
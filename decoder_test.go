@@ -52,6 +52,46 @@ b: string
 	autogold.Expect(map[string]interface{}{"a": 1, "b": "test"}).Equal(t, out)
 }
 
+func TestSchemaValidationModes(t *testing.T) {
+	const schema = `
+name: string
+replicas: number || default 1
+containers: [{
+	image: string
+	pull:  string || default "always"
+}]
+`
+	validate := func(data string, mode value.ValidationMode) (map[string]any, error) {
+		out := map[string]any{}
+		err := Unmarshal([]byte(data), &out, DecoderOption{
+			Schema:         strings.NewReader(schema),
+			ValidationMode: mode,
+		})
+		return out, err
+	}
+
+	_, err := validate(`name: "web", containers: [{pull: "never"}]`, value.ValidateStrict)
+	require.EqualError(t, err, `schema violation key containers: missing required key "image" [path containers[0]] [schema path containers[0]] [path containers]`)
+
+	out, err := validate(`name: "web", containers: [{image: "nginx"}]`, value.ValidateStrict)
+	require.NoError(t, err)
+	autogold.Expect(map[string]interface{}{
+		"containers": []interface{}{map[string]interface{}{
+			"image": "nginx",
+		}},
+		"name": "web",
+	}).Equal(t, out)
+
+	out, err = validate(`containers: [{pull: "never"}, {image: "nginx"}]`, value.ValidateDefaultsOnly)
+	require.NoError(t, err)
+	autogold.Expect(map[string]interface{}{"containers": []interface{}{map[string]interface{}{"pull": "never"}, map[string]interface{}{"image": "nginx", "pull": "always"}}, "replicas": 1}).Equal(t, out)
+
+	_, err = validate(`containers: [{pull: "never"}, {image: "nginx"}]`, value.ValidateReportMissing)
+	require.Error(t, err)
+	autogold.Expect(`missing required key "image" [path containers[0].image] [schema path containers[0].image]
+missing required key "name" [path name] [schema path name]`).Equal(t, err.Error())
+}
+
 func TestSchemaUnmarshal(t *testing.T) {
 	out := &value.FuncSchema{}
 	err := Unmarshal([]byte(testDocument), out)
@@ -0,0 +1,87 @@
+package aml
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/acorn-io/aml/pkg/migrate"
+	"github.com/acorn-io/aml/pkg/value"
+)
+
+type MigrateOption struct {
+	// Migrations is the AML source that declares the versions of the schema and the
+	// migrations between them, see the package github.com/acorn-io/aml/pkg/migrate
+	Migrations           io.Reader
+	MigrationsSourceName string
+	SourceName           string
+	// From is the version of the document, it is detected if empty
+	From string
+	// To is the version to migrate the document to, the newest version if empty
+	To string
+	// Reformat allows the whole document to be written as the result if the source can
+	// not be rewritten in place, which drops its comments and formatting
+	Reformat bool
+	Context  context.Context
+}
+
+func (o MigrateOption) Complete() MigrateOption {
+	if o.SourceName == "" {
+		o.SourceName = "<inline>"
+	}
+	if o.MigrationsSourceName == "" {
+		o.MigrationsSourceName = "<inline>"
+	}
+	if o.Context == nil {
+		o.Context = context.Background()
+	}
+	return o
+}
+
+// Migrate converts the AML document src to the version opts.To with the migrations of
+// opts.Migrations and returns the new source and the version src was detected as. The
+// fields that are not changed by the migrations keep their source and comments. If the
+// source can not be rewritten that way, for example because a changed field is computed
+// by an expression, an error is returned unless opts.Reformat is set, in which case the
+// whole document is written as the result.
+func Migrate(src []byte, opts MigrateOption) (_ []byte, from string, _ error) {
+	opts = opts.Complete()
+
+	var migrations value.Value
+	err := NewDecoder(opts.Migrations, DecoderOption{
+		SourceName: opts.MigrationsSourceName,
+		Context:    opts.Context,
+	}).Decode(&migrations)
+	if err != nil {
+		return nil, "", err
+	}
+
+	versions, err := migrate.Load(migrations)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var before value.Value
+	err = Unmarshal(src, &before, DecoderOption{
+		SourceName: opts.SourceName,
+		Context:    opts.Context,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	after, from, err := versions.Migrate(opts.Context, before, opts.From, opts.To)
+	if err != nil {
+		return nil, from, err
+	}
+
+	result, err := migrate.Rewrite(src, before, after)
+	if err == nil {
+		return result, from, nil
+	} else if !opts.Reformat {
+		return nil, from, fmt.Errorf("can not rewrite %s in place, set reformat to replace the whole document: %w", opts.SourceName, err)
+	}
+
+	result, err = Marshal(after)
+	return result, from, err
+}
@@ -0,0 +1,181 @@
+package aml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hexops/autogold/v2"
+	"github.com/stretchr/testify/require"
+)
+
+const testMigrations = `
+define V1: {
+	name:  string
+	image: string
+	port:  number
+}
+
+define V2: {
+	version: "v2"
+	name:    string
+	image:   string
+	ports: [number]
+}
+
+define V3: {
+	version: "v3"
+	name:    string
+	container: {
+		image: string
+		ports: [number]
+	}
+}
+
+versions: {
+	v1: V1
+	v2: V2
+	v3: V3
+}
+
+migrations: [{
+	from: "v1"
+	to:   "v2"
+	migrate: function {
+		args: doc: object
+		return: {
+			version: "v2"
+			name:    args.doc.name
+			image:   args.doc.image
+			ports: [args.doc.port]
+		}
+	}
+}, {
+	from: "v2"
+	to:   "v3"
+	migrate: function {
+		args: doc: object
+		return: {
+			version: "v3"
+			name:    args.doc.name
+			container: {
+				image: args.doc.image
+				ports: args.doc.ports
+			}
+		}
+	}
+}]
+`
+
+func testMigrate(src string, opts MigrateOption) (string, string, error) {
+	opts.Migrations = strings.NewReader(testMigrations)
+	result, from, err := Migrate([]byte(src), opts)
+	return string(result), from, err
+}
+
+func TestMigrate(t *testing.T) {
+	result, from, err := testMigrate(`
+// The name of the server
+name: "web"
+
+// The image to run
+image: "nginx"
+
+port: 80
+`, MigrateOption{To: "v2"})
+	require.NoError(t, err)
+	require.Equal(t, "v1", from)
+	autogold.Expect(`
+// The name of the server
+name: "web"
+
+// The image to run
+image: "nginx"
+version: "v2"
+ports: [80]
+`).Equal(t, result)
+
+	result, from, err = testMigrate(result, MigrateOption{})
+	require.NoError(t, err)
+	require.Equal(t, "v2", from)
+	autogold.Expect(`
+// The name of the server
+name: "web"
+version: "v3"
+container: {image: "nginx", ports: [80]}
+`).Equal(t, result)
+}
+
+func TestMigrateChain(t *testing.T) {
+	result, from, err := testMigrate(`
+name: "web"
+image: "nginx"
+port: 80
+`, MigrateOption{})
+	require.NoError(t, err)
+	require.Equal(t, "v1", from)
+	autogold.Expect(`
+name: "web"
+version: "v3"
+container: {image: "nginx", ports: [80]}
+`).Equal(t, result)
+}
+
+func TestMigrateComputedFields(t *testing.T) {
+	// port is removed but is not a field in the source, so the document can only be
+	// written whole, which requires Reformat
+	src := `
+name: "web"
+image: "nginx"
+{
+	port: 80
+}
+`
+	_, _, err := testMigrate(src, MigrateOption{To: "v2"})
+	require.Error(t, err)
+	autogold.Expect("can not rewrite <inline> in place, set reformat to replace the whole document: field port not found").Equal(t, err.Error())
+
+	result, _, err := testMigrate(src, MigrateOption{To: "v2", Reformat: true})
+	require.NoError(t, err)
+	autogold.Expect(`image: "nginx"
+name:  "web"
+ports: [
+80,
+]
+version: "v2"
+`).Equal(t, result)
+}
+
+func TestMigrateErrors(t *testing.T) {
+	tests := map[string]struct {
+		src  string
+		opts MigrateOption
+	}{
+		"unknown version field": {
+			src: `version: "v9", name: "web"`,
+		},
+		"no matching version": {
+			src: `name: 1`,
+		},
+		"no migration": {
+			src:  `version: "v2", name: "web", image: "nginx", ports: [80]`,
+			opts: MigrateOption{To: "v1"},
+		},
+		"invalid result": {
+			src:  `name: "web", image: "nginx", port: 80`,
+			opts: MigrateOption{From: "v2"},
+		},
+	}
+
+	errs := map[string]string{}
+	for name, test := range tests {
+		_, _, err := testMigrate(test.src, test.opts)
+		require.Error(t, err, name)
+		errs[name] = err.Error()
+	}
+	autogold.Expect(map[string]string{
+		"invalid result":        "document migrated from version v2 is not valid for version v3: schema violation key container.ports: expected kind array but got kind undefined [path container.ports] [schema path V3.container]",
+		"no matching version":   "can not detect the version of the document, it does not match the schema of any version",
+		"no migration":          "no migration from version v2 to v1",
+		"unknown version field": `unknown version "v9" in field version`,
+	}).Equal(t, errs)
+}
@@ -1,6 +1,8 @@
 package ast
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/acorn-io/aml/pkg/token"
@@ -232,12 +234,17 @@ func (g *CommentGroup) Text() string {
 // A Field represents a field declaration in a struct.
 type Field struct {
 	Label      Label
-	Constraint token.Token // token.ILLEGAL (no constraint), token.OPTION
+	Constraint token.Token // token.ILLEGAL (no constraint), token.OPTION, token.NOT (override)
 	Colon      token.Pos
 	// Match is set to the position of the match token if this is a regexp matching field and Label will be a string
 	// token that should be interpreted as a regexp
 	Match token.Pos
-	Value Expr
+	// Extends is set to the position of the extends keyword if the field is a define
+	// that extends the schemas in Bases, as in define Web extends Base: {...}
+	Extends token.Pos
+	Bases   []Expr
+	Value   Expr
+	Attrs   []*Attribute
 
 	comments
 	isDecl
@@ -246,9 +253,32 @@ type Field struct {
 func (d *Field) Pos() token.Pos  { return d.Label.Pos() }
 func (d *Field) pos() *token.Pos { return d.Label.pos() }
 func (d *Field) End() token.Pos {
+	if len(d.Attrs) > 0 {
+		return d.Attrs[len(d.Attrs)-1].End()
+	}
 	return d.Value.End()
 }
 
+// An Attribute provides meta data about a field, such as @sensitive or
+// @k8s(name="img").
+type Attribute struct {
+	At   token.Pos
+	Text string // the attribute including the @ and arguments
+
+	comments
+}
+
+func (a *Attribute) Pos() token.Pos  { return a.At }
+func (a *Attribute) pos() *token.Pos { return &a.At }
+func (a *Attribute) End() token.Pos  { return a.At.Add(len(a.Text)) }
+
+// Split returns the name of the attribute and the text between its parentheses,
+// which is empty if the attribute has no arguments.
+func (a *Attribute) Split() (name, body string) {
+	name, body, _ = strings.Cut(strings.TrimPrefix(a.Text, "@"), "(")
+	return name, strings.TrimSuffix(body, ")")
+}
+
 // ----------------------------------------------------------------------------
 // Expressions and types
 //
@@ -260,6 +290,7 @@ func (d *Field) End() token.Pos {
 // created.
 type BadExpr struct {
 	From, To token.Pos // position range of bad expression
+	Text     string    // source text of the range, if known
 
 	comments
 	isExpr
@@ -309,7 +340,7 @@ type Interpolation struct {
 
 func (x *Interpolation) Pos() token.Pos  { return x.Elts[0].Pos() }
 func (x *Interpolation) pos() *token.Pos { return x.Elts[0].pos() }
-func (x *Interpolation) End() token.Pos  { return x.Elts[len(x.Elts)-1].Pos() }
+func (x *Interpolation) End() token.Pos  { return x.Elts[len(x.Elts)-1].End() }
 
 // A Func node represents a function expression.
 type Func struct {
@@ -401,7 +432,10 @@ type ListComprehension struct {
 	For    token.Pos
 	Rbrack token.Pos // position of "]"
 	Clause *ForClause
-	Value  Expr
+	// Clauses are the for, if and let clauses that follow Clause, as in
+	// [for a in xs for b in a.items if b.enabled b.name]
+	Clauses []Clause
+	Value   Expr
 
 	comments
 	isExpr
@@ -415,8 +449,10 @@ func (x *ListComprehension) End() token.Pos  { return x.Rbrack.Add(1) }
 type For struct {
 	For    token.Pos
 	Clause *ForClause
-	Struct *StructLit
-	Else   *Else
+	// Clauses are the for, if and let clauses that follow Clause
+	Clauses []Clause
+	Struct  *StructLit
+	Else    *Else
 
 	comments
 	isExpr
@@ -433,27 +469,39 @@ func (x *For) End() token.Pos {
 
 // A ForClause node represents a for clause in a comprehension.
 type ForClause struct {
-	Key    *Ident
-	Comma  token.Pos
-	Value  *Ident
-	In     token.Pos
-	Source Expr
+	// For is the position of "for" if the clause follows another clause. The
+	// position of the first "for" is held by the For or ListComprehension node.
+	For   token.Pos
+	Key   *Ident
+	Comma token.Pos
+	Value *Ident
+	// Pattern is set instead of Value if the value is destructured, as in
+	// for {name, port} in services
+	Pattern Expr
+	In      token.Pos
+	Source  Expr
 
 	comments
 	isClause
 }
 
 func (x *ForClause) Pos() token.Pos {
-	if x.Key == nil {
-		return x.Value.Pos()
+	if x.Key != nil {
+		return x.Key.Pos()
 	}
-	return x.Key.Pos()
+	if x.Pattern != nil {
+		return x.Pattern.Pos()
+	}
+	return x.Value.Pos()
 }
 func (x *ForClause) pos() *token.Pos {
-	if x.Key == nil {
-		return x.Value.pos()
+	if x.Key != nil {
+		return x.Key.pos()
+	}
+	if x.Pattern != nil {
+		return x.Pattern.pos()
 	}
-	return x.Key.pos()
+	return x.Value.pos()
 }
 func (x *ForClause) End() token.Pos { return x.Source.End() }
 
@@ -470,7 +518,45 @@ type If struct {
 
 func (x *If) Pos() token.Pos  { return x.If }
 func (x *If) pos() *token.Pos { return &x.If }
-func (x *If) End() token.Pos  { return x.Struct.End() }
+func (x *If) End() token.Pos {
+	if x.Else != nil {
+		return x.Else.End()
+	}
+	return x.Struct.End()
+}
+
+// A ConditionalExpr node represents an expression of the form
+// if Condition then X else Y, which evaluates to either X or Y.
+type ConditionalExpr struct {
+	If        token.Pos
+	Condition Expr
+	Then      token.Pos
+	X         Expr
+	Else      token.Pos
+	Y         Expr
+
+	comments
+	isExpr
+}
+
+func (x *ConditionalExpr) Pos() token.Pos  { return x.If }
+func (x *ConditionalExpr) pos() *token.Pos { return &x.If }
+func (x *ConditionalExpr) End() token.Pos  { return x.Y.End() }
+
+// A Spread node represents ...X in a struct or list literal, which adds all the
+// fields of the object X to the struct or all the elements of the array X to the list.
+type Spread struct {
+	Ellipsis token.Pos // position of "..."
+	X        Expr
+
+	comments
+	isExpr
+	isDecl
+}
+
+func (x *Spread) Pos() token.Pos  { return x.Ellipsis }
+func (x *Spread) pos() *token.Pos { return &x.Ellipsis }
+func (x *Spread) End() token.Pos  { return x.X.End() }
 
 // An Else node represents an else or else if expression after an if expression
 type Else struct {
@@ -493,6 +579,8 @@ func (x *Else) End() token.Pos {
 
 // A IfClause node represents an if guard clause in a comprehension.
 type IfClause struct {
+	// If is the position of "if" if the clause follows another clause
+	If        token.Pos
 	Condition Expr
 
 	comments
@@ -507,8 +595,11 @@ func (x *IfClause) End() token.Pos  { return x.Condition.End() }
 type LetClause struct {
 	Let   token.Pos
 	Ident *Ident
-	Colon token.Pos
-	Expr  Expr
+	// Pattern is set instead of Ident if the value is destructured, as in
+	// let [host, port]: std.split(addr, ":")
+	Pattern Expr
+	Colon   token.Pos
+	Expr    Expr
 
 	comments
 	isClause
@@ -547,8 +638,9 @@ func (x *DefaultExpr) End() token.Pos  { return x.X.End() }
 
 // A SelectorExpr node represents an expression followed by a selector.
 type SelectorExpr struct {
-	X   Expr  // expression
-	Sel Label // field selector
+	X        Expr  // expression
+	Sel      Label // field selector
+	Optional bool  // true for ?. which yields no value instead of failing if X is null
 
 	comments
 	isExpr
@@ -560,10 +652,11 @@ func (x *SelectorExpr) End() token.Pos  { return x.Sel.End() }
 
 // An IndexExpr node represents an expression followed by an index.
 type IndexExpr struct {
-	X      Expr      // expression
-	Lbrack token.Pos // position of "["
-	Index  Expr      // index expression
-	Rbrack token.Pos // position of "]"
+	X        Expr      // expression
+	Lbrack   token.Pos // position of "[" or "?["
+	Index    Expr      // index expression
+	Rbrack   token.Pos // position of "]"
+	Optional bool      // true for ?[ which yields no value instead of failing if X is null or the index is missing
 
 	comments
 	isExpr
@@ -643,6 +736,75 @@ func (x *Ident) String() string {
 	return "<nil>"
 }
 
+// ----------------------------------------------------------------------------
+// Constructors
+//
+// Nodes created by these functions have no file position and are printed with
+// the default layout of the formatter.
+
+// NewIdent creates a new identifier
+func NewIdent(name string) *Ident {
+	return &Ident{Name: name}
+}
+
+// NewString creates a new string literal with the quoted value of s
+func NewString(s string) *BasicLit {
+	return &BasicLit{
+		Kind:  token.STRING,
+		Value: strconv.Quote(s),
+	}
+}
+
+// NewLabel creates an identifier for name if it is a valid identifier and a string
+// literal otherwise
+func NewLabel(name string) Label {
+	if IsValidIdent(name) {
+		return NewIdent(name)
+	}
+	return NewString(name)
+}
+
+// NewStruct creates a struct from a list of declarations and label and value pairs.
+// A label is either a Label or a string, which is converted with NewLabel, and must be
+// followed by an Expr, as in
+//
+//	NewStruct("image", NewString("nginx"), "replicas", &BasicLit{...})
+func NewStruct(fields ...any) *StructLit {
+	s := &StructLit{
+		// braces are always printed, even for a single field
+		Lbrace: token.Blank.Pos(),
+		Rbrace: token.Newline.Pos(),
+	}
+	for i := 0; i < len(fields); i++ {
+		var label Label
+		switch x := fields[i].(type) {
+		case Decl:
+			s.Elts = append(s.Elts, x)
+			continue
+		case string:
+			label = NewLabel(x)
+		case Label:
+			label = x
+		default:
+			panic(fmt.Sprintf("NewStruct: invalid label type %T", x))
+		}
+
+		i++
+		if i >= len(fields) {
+			panic(fmt.Sprintf("NewStruct: missing value for label %v", fields[i-1]))
+		}
+		expr, ok := fields[i].(Expr)
+		if !ok {
+			panic(fmt.Sprintf("NewStruct: invalid value type %T", fields[i]))
+		}
+		s.Elts = append(s.Elts, &Field{
+			Label: label,
+			Value: expr,
+		})
+	}
+	return s
+}
+
 // ----------------------------------------------------------------------------
 // Declarations
 
@@ -651,6 +813,7 @@ func (x *Ident) String() string {
 // created.
 type BadDecl struct {
 	From, To token.Pos // position range of bad declaration
+	Text     string    // source text of the range, if known
 
 	comments
 	isDecl
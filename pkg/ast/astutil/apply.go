@@ -0,0 +1,292 @@
+// Package astutil contains utilities for rewriting syntax trees.
+package astutil
+
+import (
+	"fmt"
+
+	"github.com/acorn-io/aml/pkg/ast"
+)
+
+// An ApplyFunc is invoked by Apply for each node n before and/or after the node's
+// children, using a Cursor describing the current node and providing operations on it.
+//
+// The return value of ApplyFunc controls the syntax tree traversal.
+// See Apply for details.
+type ApplyFunc func(*Cursor) bool
+
+// Apply traverses a syntax tree recursively, starting with root, and calling pre and
+// post for each node as described below. Apply returns the syntax tree, possibly
+// modified.
+//
+// If pre is not nil, it is called for each node before the node's children are
+// traversed (pre-order). If pre returns false, no children are traversed, and post is
+// not called for that node.
+//
+// If post is not nil, and a prior call of pre didn't return false, post is called for
+// each node after its children are traversed (post-order). If post returns false,
+// traversal is terminated and Apply returns immediately.
+//
+// Only fields that refer to AST nodes are considered children; comments are not
+// traversed. Nil children are skipped.
+//
+// Children are traversed in the order in which they appear in the respective node's
+// struct definition. Nodes inserted with InsertBefore or InsertAfter are not
+// traversed, but a node that replaces the current one in pre is.
+func Apply(root ast.Node, pre, post ApplyFunc) (result ast.Node) {
+	parent := &struct{ ast.Node }{root}
+	defer func() {
+		if r := recover(); r != nil && r != abort {
+			panic(r)
+		}
+		result = parent.Node
+	}()
+
+	a := &application{
+		pre:  pre,
+		post: post,
+	}
+	a.apply(&Cursor{
+		node: root,
+		set: func(n ast.Node) {
+			parent.Node = n
+		},
+	})
+	return
+}
+
+var abort = new(int) // singleton, to signal termination of Apply
+
+// A Cursor describes a node encountered during Apply. Information about the node and
+// its parent is available from the Node, Parent, Name, and Index methods.
+type Cursor struct {
+	parent ast.Node
+	name   string
+	node   ast.Node
+	set    func(ast.Node)
+
+	// list and index are set if the node is an element of a list
+	list  nodeList
+	index int
+	step  int
+}
+
+// Node returns the current node
+func (c *Cursor) Node() ast.Node { return c.node }
+
+// Parent returns the parent of the current node, or nil for the root
+func (c *Cursor) Parent() ast.Node { return c.parent }
+
+// Name returns the name of the parent node field that contains the current node,
+// such as "Elts" or "Value"
+func (c *Cursor) Name() string { return c.name }
+
+// Index reports the index of the current node in the list of the parent node field
+// that contains it, or a value < 0 if the current node is not part of a list.
+func (c *Cursor) Index() int {
+	if c.list == nil {
+		return -1
+	}
+	return c.index
+}
+
+// Replace replaces the current node with n. If Replace is called in pre the children
+// of n are traversed. Replace panics if n can not be stored in the field of the parent.
+func (c *Cursor) Replace(n ast.Node) {
+	c.set(n)
+	c.node = n
+}
+
+// Delete deletes the current node from its containing list. If the current node is
+// not part of a list, Delete panics.
+func (c *Cursor) Delete() {
+	c.mustList("Delete")
+	c.list.delete(c.index)
+	c.index--
+}
+
+// InsertAfter inserts n after the current node in its containing list. If the
+// current node is not part of a list, InsertAfter panics. Apply does not traverse n.
+func (c *Cursor) InsertAfter(n ast.Node) {
+	c.mustList("InsertAfter")
+	c.list.insert(c.index+1, n)
+	c.step++
+}
+
+// InsertBefore inserts n before the current node in its containing list. If the
+// current node is not part of a list, InsertBefore panics. Apply does not traverse n.
+func (c *Cursor) InsertBefore(n ast.Node) {
+	c.mustList("InsertBefore")
+	c.list.insert(c.index, n)
+	c.index++
+}
+
+func (c *Cursor) mustList(op string) {
+	if c.list == nil {
+		panic(fmt.Sprintf("%s: node %T in field %s is not part of a list", op, c.node, c.name))
+	}
+}
+
+// nodeList is a slice of nodes in a field of a parent node
+type nodeList interface {
+	len() int
+	get(i int) ast.Node
+	set(i int, n ast.Node)
+	delete(i int)
+	insert(i int, n ast.Node)
+}
+
+type list[T ast.Node] struct {
+	elems *[]T
+}
+
+func (l list[T]) len() int              { return len(*l.elems) }
+func (l list[T]) get(i int) ast.Node    { return (*l.elems)[i] }
+func (l list[T]) set(i int, n ast.Node) { (*l.elems)[i] = n.(T) }
+
+func (l list[T]) delete(i int) {
+	*l.elems = append((*l.elems)[:i], (*l.elems)[i+1:]...)
+}
+
+func (l list[T]) insert(i int, n ast.Node) {
+	var zero T
+	*l.elems = append(*l.elems, zero)
+	copy((*l.elems)[i+1:], (*l.elems)[i:])
+	(*l.elems)[i] = n.(T)
+}
+
+type application struct {
+	pre, post ApplyFunc
+}
+
+func (a *application) apply(c *Cursor) {
+	if a.pre != nil && !a.pre(c) {
+		return
+	}
+	a.children(c.node)
+	if a.post != nil && !a.post(c) {
+		panic(abort)
+	}
+}
+
+// field applies to a child node stored in a field of parent
+func field[T interface {
+	ast.Node
+	comparable
+}](a *application, parent ast.Node, name string, ptr *T) {
+	var zero T
+	if *ptr == zero {
+		return
+	}
+	a.apply(&Cursor{
+		parent: parent,
+		name:   name,
+		node:   *ptr,
+		set: func(n ast.Node) {
+			*ptr = n.(T)
+		},
+	})
+}
+
+// elems applies to each node of a list stored in a field of parent
+func elems[T ast.Node](a *application, parent ast.Node, name string, ptr *[]T) {
+	c := &Cursor{
+		parent: parent,
+		name:   name,
+		list:   list[T]{elems: ptr},
+	}
+	c.set = func(n ast.Node) {
+		c.list.set(c.index, n)
+	}
+	for c.index = 0; c.index < c.list.len(); c.index += c.step {
+		c.step = 1
+		c.node = c.list.get(c.index)
+		a.apply(c)
+	}
+}
+
+func (a *application) children(n ast.Node) {
+	switch n := n.(type) {
+	case *ast.File:
+		elems(a, n, "Decls", &n.Decls)
+	case *ast.Field:
+		field(a, n, "Label", &n.Label)
+		elems(a, n, "Bases", &n.Bases)
+		field(a, n, "Value", &n.Value)
+		elems(a, n, "Attrs", &n.Attrs)
+	case *ast.Func:
+		field(a, n, "Body", &n.Body)
+		field(a, n, "ReturnType", &n.ReturnType)
+	case *ast.Lambda:
+		elems(a, n, "Idents", &n.Idents)
+		field(a, n, "Expr", &n.Expr)
+	case *ast.StructLit:
+		elems(a, n, "Elts", &n.Elts)
+	case *ast.SchemaLit:
+		field(a, n, "Decl", &n.Decl)
+	case *ast.ListLit:
+		elems(a, n, "Elts", &n.Elts)
+	case *ast.ListComprehension:
+		field(a, n, "Clause", &n.Clause)
+		elems(a, n, "Clauses", &n.Clauses)
+		field(a, n, "Value", &n.Value)
+	case *ast.Interpolation:
+		elems(a, n, "Elts", &n.Elts)
+	case *ast.For:
+		field(a, n, "Clause", &n.Clause)
+		elems(a, n, "Clauses", &n.Clauses)
+		field(a, n, "Struct", &n.Struct)
+		field(a, n, "Else", &n.Else)
+	case *ast.ForClause:
+		field(a, n, "Key", &n.Key)
+		field(a, n, "Value", &n.Value)
+		field(a, n, "Pattern", &n.Pattern)
+		field(a, n, "Source", &n.Source)
+	case *ast.Spread:
+		field(a, n, "X", &n.X)
+	case *ast.ConditionalExpr:
+		field(a, n, "Condition", &n.Condition)
+		field(a, n, "X", &n.X)
+		field(a, n, "Y", &n.Y)
+	case *ast.If:
+		field(a, n, "Condition", &n.Condition)
+		field(a, n, "Struct", &n.Struct)
+		field(a, n, "Else", &n.Else)
+	case *ast.Else:
+		field(a, n, "If", &n.If)
+		field(a, n, "Struct", &n.Struct)
+	case *ast.IfClause:
+		field(a, n, "Condition", &n.Condition)
+	case *ast.LetClause:
+		field(a, n, "Ident", &n.Ident)
+		field(a, n, "Pattern", &n.Pattern)
+		field(a, n, "Expr", &n.Expr)
+	case *ast.ParenExpr:
+		field(a, n, "X", &n.X)
+	case *ast.DefaultExpr:
+		field(a, n, "X", &n.X)
+	case *ast.SelectorExpr:
+		field(a, n, "X", &n.X)
+		field(a, n, "Sel", &n.Sel)
+	case *ast.IndexExpr:
+		field(a, n, "X", &n.X)
+		field(a, n, "Index", &n.Index)
+	case *ast.SliceExpr:
+		field(a, n, "X", &n.X)
+		field(a, n, "Low", &n.Low)
+		field(a, n, "High", &n.High)
+	case *ast.CallExpr:
+		field(a, n, "Fun", &n.Fun)
+		elems(a, n, "Args", &n.Args)
+	case *ast.UnaryExpr:
+		field(a, n, "X", &n.X)
+	case *ast.BinaryExpr:
+		field(a, n, "X", &n.X)
+		field(a, n, "Y", &n.Y)
+	case *ast.EmbedDecl:
+		field(a, n, "Expr", &n.Expr)
+	case nil, *ast.Ident, *ast.BasicLit, *ast.Attribute, *ast.BadExpr, *ast.BadDecl, *ast.Comment, *ast.CommentGroup:
+		// leaves
+	default:
+		panic(fmt.Sprintf("Apply: unexpected node type %T", n))
+	}
+}
@@ -0,0 +1,156 @@
+package astutil
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/acorn-io/aml/pkg/ast"
+	"github.com/acorn-io/aml/pkg/format"
+	"github.com/acorn-io/aml/pkg/parser"
+	"github.com/hexops/autogold/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func fieldName(n ast.Node) string {
+	if f, ok := n.(*ast.Field); ok {
+		if ident, ok := f.Label.(*ast.Ident); ok {
+			return ident.Name
+		}
+	}
+	return ""
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name      string
+		pre, post ApplyFunc
+	}{
+		{
+			name: "rename",
+			pre: func(c *Cursor) bool {
+				if ident, ok := c.Node().(*ast.Ident); ok && ident.Name == "replicas" {
+					c.Replace(ast.NewIdent("scale"))
+				}
+				return true
+			},
+		},
+		{
+			name: "delete",
+			pre: func(c *Cursor) bool {
+				if fieldName(c.Node()) == "image" || fieldName(c.Node()) == "debug" {
+					c.Delete()
+				}
+				return true
+			},
+		},
+		{
+			name: "insert",
+			pre: func(c *Cursor) bool {
+				switch fieldName(c.Node()) {
+				case "web":
+					c.InsertBefore(&ast.Field{
+						Label: ast.NewIdent("db"),
+						Value: ast.NewStruct("image", ast.NewString("mysql")),
+					})
+				case "worker":
+					c.InsertAfter(&ast.Field{
+						Label: ast.NewIdent("cron"),
+						Value: ast.NewStruct(
+							"image", ast.NewString("cron"),
+							"schedule-spec", ast.NewString("@daily"),
+						),
+					})
+				}
+				return true
+			},
+		},
+		{
+			name: "list-elements",
+			pre: func(c *Cursor) bool {
+				lit, ok := c.Node().(*ast.BasicLit)
+				if !ok || c.Name() != "Elts" {
+					return true
+				}
+				switch lit.Value {
+				case `"A=1"`:
+					c.Replace(ast.NewString("A=2"))
+				case `"B=2"`:
+					c.InsertAfter(ast.NewString("C=3"))
+					c.InsertBefore(ast.NewString("B=1"))
+				}
+				return true
+			},
+		},
+		{
+			name: "skip-children",
+			pre: func(c *Cursor) bool {
+				if fieldName(c.Node()) == "web" {
+					return false
+				}
+				if _, ok := c.Node().(*ast.BasicLit); ok && c.Name() == "Value" {
+					c.Replace(ast.NewString("changed"))
+				}
+				return true
+			},
+		},
+		{
+			name: "stop",
+			post: func(c *Cursor) bool {
+				if _, ok := c.Node().(*ast.BasicLit); ok && c.Name() == "Value" {
+					c.Replace(ast.NewString("changed"))
+					return false
+				}
+				return true
+			},
+		},
+	}
+
+	data, err := os.ReadFile("testdata/TestApply/input.acorn")
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := parser.ParseFile("input.acorn", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			result := Apply(f, tt.pre, tt.post)
+			require.Same(t, f, result)
+
+			out, err := format.Node(result)
+			require.NoError(t, err)
+			autogold.ExpectFile(t, autogold.Raw(out))
+		})
+	}
+}
+
+func TestApplyReplaceRoot(t *testing.T) {
+	expr, err := parser.ParseExpr("", bytes.NewReader([]byte("a + b")))
+	require.NoError(t, err)
+
+	result := Apply(expr, func(c *Cursor) bool {
+		if c.Parent() == nil {
+			require.Equal(t, -1, c.Index())
+			c.Replace(ast.NewStruct("value", c.Node().(ast.Expr)))
+		}
+		return true
+	}, nil)
+
+	out, err := format.Node(result)
+	require.NoError(t, err)
+	require.Equal(t, "{\n\tvalue: a + b\n}", string(out))
+}
+
+func TestApplyPanics(t *testing.T) {
+	expr, err := parser.ParseExpr("", bytes.NewReader([]byte("a + b")))
+	require.NoError(t, err)
+
+	require.PanicsWithValue(t, "Delete: node *ast.Ident in field X is not part of a list", func() {
+		Apply(expr, func(c *Cursor) bool {
+			if c.Name() == "X" {
+				c.Delete()
+			}
+			return true
+		}, nil)
+	})
+}
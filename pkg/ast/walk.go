@@ -81,9 +81,15 @@ func walk(v visitor, node Node) {
 
 	case *Field:
 		walk(v, n.Label)
+		for _, base := range n.Bases {
+			walk(v, base)
+		}
 		if n.Value != nil {
 			walk(v, n.Value)
 		}
+		for _, a := range n.Attrs {
+			walk(v, a)
+		}
 
 	case *Func:
 		walk(v, n.Body)
@@ -92,7 +98,7 @@ func walk(v visitor, node Node) {
 		walkDeclList(v, n.Elts)
 
 	// Expressions
-	case *BadExpr, *Ident, *BasicLit:
+	case *BadExpr, *Ident, *BasicLit, *Attribute:
 		// nothing to do
 
 	case *Interpolation:
@@ -141,13 +147,35 @@ func walk(v visitor, node Node) {
 		walk(v, n.Expr)
 
 	case *LetClause:
-		walk(v, n.Ident)
+		if n.Pattern != nil {
+			walk(v, n.Pattern)
+		} else {
+			walk(v, n.Ident)
+		}
 		walk(v, n.Expr)
 
+	case *ListComprehension:
+		walk(v, n.Clause)
+		for _, c := range n.Clauses {
+			walk(v, c)
+		}
+		walk(v, n.Value)
+
 	case *For:
 		walk(v, n.Clause)
+		for _, c := range n.Clauses {
+			walk(v, c)
+		}
 		walk(v, n.Struct)
 
+	case *Spread:
+		walk(v, n.X)
+
+	case *ConditionalExpr:
+		walk(v, n.Condition)
+		walk(v, n.X)
+		walk(v, n.Y)
+
 	case *If:
 		walk(v, n.Condition)
 		walk(v, n.Struct)
@@ -171,7 +199,11 @@ func walk(v visitor, node Node) {
 		if n.Key != nil {
 			walk(v, n.Key)
 		}
-		walk(v, n.Value)
+		if n.Pattern != nil {
+			walk(v, n.Pattern)
+		} else {
+			walk(v, n.Value)
+		}
 		walk(v, n.Source)
 
 	case *IfClause:
@@ -0,0 +1,321 @@
+// Package edit reads and rewrites the values of fields in AML source addressed by a
+// path such as containers.web.image, keeping the formatting and comments of the rest
+// of the file.
+package edit
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/acorn-io/aml/pkg/ast"
+	"github.com/acorn-io/aml/pkg/format"
+	"github.com/acorn-io/aml/pkg/parser"
+	"github.com/acorn-io/aml/pkg/token"
+	"github.com/acorn-io/aml/pkg/value"
+)
+
+// Get returns the source text of the value at path
+func Get(src []byte, path string) (string, error) {
+	_, target, err := lookup(src, path, false)
+	if err != nil {
+		return "", err
+	}
+
+	expr := target.get()
+	if start, end := expr.Pos(), expr.End(); start.IsValid() && end.IsValid() {
+		return string(src[start.Offset():end.Offset()]), nil
+	}
+
+	out, err := format.Node(expr)
+	return string(out), err
+}
+
+// Set replaces the value at path with expr, which is parsed as an expression. Structs
+// along the path that do not have the field are extended with it.
+func Set(src []byte, path, expr string) ([]byte, error) {
+	newValue, err := parseExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	return SetExpr(src, path, newValue)
+}
+
+// SetString replaces the value at path with a string literal of s
+func SetString(src []byte, path, s string) ([]byte, error) {
+	return SetExpr(src, path, ast.NewString(s))
+}
+
+// SetExpr replaces the value at path with expr
+func SetExpr(src []byte, path string, expr ast.Expr) ([]byte, error) {
+	s, target, err := lookup(src, path, true)
+	if err != nil {
+		return nil, err
+	}
+	target.set(expr)
+	return s.Format()
+}
+
+// Append parses expr and adds it to the end of the list at path
+func Append(src []byte, path, expr string) ([]byte, error) {
+	newValue, err := parseExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	s, target, err := lookup(src, path, false)
+	if err != nil {
+		return nil, err
+	}
+
+	list, ok := target.get().(*ast.ListLit)
+	if !ok {
+		return nil, fmt.Errorf("value at %s is not a list", path)
+	}
+	list.Elts = append(list.Elts, newValue)
+	return s.Format()
+}
+
+// Delete removes the field or list element at path
+func Delete(src []byte, path string) ([]byte, error) {
+	s, target, err := lookup(src, path, false)
+	if err != nil {
+		return nil, err
+	}
+	target.delete()
+	return s.Format()
+}
+
+func parseExpr(expr string) (ast.Expr, error) {
+	result, err := parser.ParseExpr("", strings.NewReader(expr))
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", expr, err)
+	}
+	return result, nil
+}
+
+// location is a field in a list of declarations or an element of a list
+type location struct {
+	decls *[]ast.Decl
+	field *ast.Field
+
+	list  *ast.ListLit
+	index int
+}
+
+func (l *location) get() ast.Expr {
+	if l.list != nil {
+		return l.list.Elts[l.index]
+	}
+	return l.field.Value
+}
+
+func (l *location) set(expr ast.Expr) {
+	if l.list != nil {
+		l.list.Elts[l.index] = expr
+	} else {
+		l.field.Value = expr
+	}
+}
+
+func (l *location) delete() {
+	if l.list != nil {
+		l.list.Elts = append(l.list.Elts[:l.index:l.index], l.list.Elts[l.index+1:]...)
+		return
+	}
+	var result []ast.Decl
+	for _, decl := range *l.decls {
+		if decl != l.field {
+			result = append(result, decl)
+		}
+	}
+	*l.decls = result
+}
+
+// lookup parses src and finds the location of path. If create is set fields that do
+// not exist are added to their struct.
+func lookup(src []byte, path string, create bool) (*format.Source, *location, error) {
+	elems, err := parsePath(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s, err := format.ParseSource("", src)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		decls  = &s.File.Decls
+		target *location
+	)
+	for i, elem := range elems {
+		if elem.index >= 0 {
+			target, err = nextIndex(target, elems[:i+1])
+			if err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		if i > 0 {
+			decls, err = structDecls(target.get(), elems[:i])
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		target = find(decls, elem.key)
+		if target == nil && !create {
+			return nil, nil, fmt.Errorf("field %s not found", formatPath(elems[:i+1]))
+		} else if target == nil {
+			// the value is replaced by the caller or filled in by the rest of the path
+			field := &ast.Field{
+				Label: ast.NewLabel(elem.key),
+				Value: &ast.StructLit{},
+			}
+			*decls = append(*decls, field)
+			target = &location{
+				decls: decls,
+				field: field,
+			}
+		}
+	}
+
+	return s, target, nil
+}
+
+// find returns the first field with the given key in decls
+func find(decls *[]ast.Decl, key string) *location {
+	for _, decl := range *decls {
+		field, ok := decl.(*ast.Field)
+		if !ok || field.Match.IsValid() {
+			continue
+		}
+		if label, ok := labelKey(field.Label); ok && label == key {
+			return &location{
+				decls: decls,
+				field: field,
+			}
+		}
+	}
+	return nil
+}
+
+// nextIndex returns the element of the list at target selected by the last element of path
+func nextIndex(target *location, path []pathElem) (*location, error) {
+	elem := path[len(path)-1]
+	list, ok := target.get().(*ast.ListLit)
+	if !ok {
+		return nil, fmt.Errorf("value at %s is not a list", formatPath(path[:len(path)-1]))
+	}
+	if elem.index >= len(list.Elts) {
+		return nil, fmt.Errorf("index %d out of range for list of length %d at %s",
+			elem.index, len(list.Elts), formatPath(path[:len(path)-1]))
+	}
+	return &location{
+		list:  list,
+		index: elem.index,
+	}, nil
+}
+
+func structDecls(expr ast.Expr, path []pathElem) (*[]ast.Decl, error) {
+	s, ok := expr.(*ast.StructLit)
+	if !ok {
+		return nil, fmt.Errorf("value at %s is not a struct", formatPath(path))
+	}
+	return &s.Elts, nil
+}
+
+func labelKey(label ast.Label) (string, bool) {
+	switch n := label.(type) {
+	case *ast.Ident:
+		return n.Name, true
+	case *ast.BasicLit:
+		if n.Kind != token.STRING {
+			return "", false
+		}
+		s, err := value.Unquote(n.Value)
+		return s, err == nil
+	}
+	return "", false
+}
+
+// pathElem is a field name, or a list index if index is not negative
+type pathElem struct {
+	key   string
+	index int
+}
+
+// parsePath parses a path of field names separated by dots and list indexes in
+// brackets, such as containers.web.ports[0]. Field names that are not identifiers
+// can be quoted, as in labels."app.kubernetes.io/name".
+func parsePath(path string) (result []pathElem, _ error) {
+	rest := path
+	for rest != "" {
+		var key string
+		if strings.HasPrefix(rest, `"`) {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return nil, fmt.Errorf("invalid path %q: %w", path, err)
+			}
+			key, _ = strconv.Unquote(quoted)
+			rest = rest[len(quoted):]
+		} else {
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key, rest = rest[:end], rest[end:]
+		}
+		if key == "" {
+			return nil, fmt.Errorf("invalid path %q: missing field name", path)
+		}
+		result = append(result, pathElem{key: key, index: -1})
+
+		for strings.HasPrefix(rest, "[") {
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: missing ]", path)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid path %q: invalid index %q", path, rest[1:end])
+			}
+			result = append(result, pathElem{index: index})
+			rest = rest[end+1:]
+		}
+
+		if rest != "" {
+			if !strings.HasPrefix(rest, ".") || len(rest) == 1 {
+				return nil, fmt.Errorf("invalid path %q", path)
+			}
+			rest = rest[1:]
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("invalid empty path")
+	}
+	return result, nil
+}
+
+func formatPath(path []pathElem) string {
+	buf := &bytes.Buffer{}
+	for _, elem := range path {
+		switch {
+		case elem.index >= 0:
+			fmt.Fprintf(buf, "[%d]", elem.index)
+		case buf.Len() > 0 && ast.IsValidIdent(elem.key):
+			buf.WriteString("." + elem.key)
+		case ast.IsValidIdent(elem.key):
+			buf.WriteString(elem.key)
+		case buf.Len() > 0:
+			buf.WriteString("." + strconv.Quote(elem.key))
+		default:
+			buf.WriteString(strconv.Quote(elem.key))
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,116 @@
+package edit
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hexops/autogold/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	data, err := os.ReadFile("testdata/TestEdit/input.acorn")
+	require.NoError(t, err)
+
+	tests := map[string]string{
+		"containers.web.image":           `"nginx:1.24"`,
+		"containers.web.ports":           `[80, 443]`,
+		"containers.web.ports[1]":        `443`,
+		`containers.web.env."LOG_LEVEL"`: `"info"`,
+		"containers.web.env.LOG_LEVEL":   `"info"`,
+		"volumes[1].name":                `"logs"`,
+	}
+	for path, expected := range tests {
+		actual, err := Get(data, path)
+		require.NoError(t, err, path)
+		require.Equal(t, expected, actual, path)
+	}
+
+	errors := map[string]string{
+		"containers.db":           "field containers.db not found",
+		"containers.web.image[0]": "value at containers.web.image is not a list",
+		"containers.web.ports[5]": "index 5 out of range for list of length 2 at containers.web.ports",
+		"containers..web":         `invalid path "containers..web": missing field name`,
+		"volumes[x]":              `invalid path "volumes[x]": invalid index "x"`,
+	}
+	for path, expected := range errors {
+		_, err := Get(data, path)
+		require.EqualError(t, err, expected, path)
+	}
+}
+
+func TestEdit(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(data []byte) ([]byte, error)
+	}{
+		{
+			name: "set-string",
+			edit: func(data []byte) ([]byte, error) {
+				return SetString(data, "containers.web.image", "nginx:1.25")
+			},
+		},
+		{
+			name: "set-expr",
+			edit: func(data []byte) ([]byte, error) {
+				return Set(data, "containers.web.ports[0]", "8080 + 1")
+			},
+		},
+		{
+			name: "set-new-field",
+			edit: func(data []byte) ([]byte, error) {
+				return Set(data, "containers.web.env.DEBUG", `"true"`)
+			},
+		},
+		{
+			name: "set-new-path",
+			edit: func(data []byte) ([]byte, error) {
+				return Set(data, `labels."app.kubernetes.io/name"`, `"web"`)
+			},
+		},
+		{
+			name: "append",
+			edit: func(data []byte) ([]byte, error) {
+				return Append(data, "volumes", `{name: "cache"}`)
+			},
+		},
+		{
+			name: "delete-field",
+			edit: func(data []byte) ([]byte, error) {
+				return Delete(data, "containers.web.image")
+			},
+		},
+		{
+			name: "delete-element",
+			edit: func(data []byte) ([]byte, error) {
+				return Delete(data, "volumes[0]")
+			},
+		},
+		{
+			name: "append-not-list-err",
+			edit: func(data []byte) ([]byte, error) {
+				return Append(data, "containers.web.image", `"x"`)
+			},
+		},
+		{
+			name: "set-invalid-expr-err",
+			edit: func(data []byte) ([]byte, error) {
+				return Set(data, "containers.web.image", `{`)
+			},
+		},
+	}
+
+	data, err := os.ReadFile("testdata/TestEdit/input.acorn")
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := tt.edit(data)
+			if err != nil {
+				autogold.ExpectFile(t, err)
+			} else {
+				autogold.ExpectFile(t, autogold.Raw(out))
+			}
+		})
+	}
+}
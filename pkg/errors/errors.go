@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/acorn-io/aml/pkg/token"
 	"github.com/acorn-io/aml/pkg/value"
@@ -138,3 +139,26 @@ func (e *ErrValueNotDefined) Error() string {
 func (e *ErrValueNotDefined) Pos() value.Position {
 	return e.Position
 }
+
+// ErrConflict is returned when a field can not be merged with a previous field with
+// the same key. Previous is the position of the previous field. Path is the path to the
+// key if the conflict is in nested objects, as in resources.cpu.
+type ErrConflict struct {
+	Path     []string
+	Key      string
+	Previous value.Position
+	Err      error
+}
+
+func (e *ErrConflict) Error() string {
+	name := e.Key
+	if len(e.Path) > 0 {
+		name = strings.Join(e.Path, ".")
+	}
+	return fmt.Sprintf("field %s conflicts with the value at %s, use %s!: to override it: %v",
+		name, e.Previous, e.Key, e.Err)
+}
+
+func (e *ErrConflict) Unwrap() error {
+	return e.Err
+}
@@ -37,26 +37,36 @@ func (a *Array) ToValue(ctx context.Context) (value.Value, bool, error) {
 			return undef, true, nil
 		}
 
-		objs = append(objs, v)
-		if IsSchema(ctx) {
-			if value.IsSimpleKind(v.Kind()) {
-				if _, ok := kinds[v.Kind()]; ok {
-					continue
-				} else {
-					kinds[v.Kind()] = struct{}{}
-					v = &value.TypeSchema{
-						KindValue: v.Kind(),
-						Path:      value.GetPath(ctx),
+		values := []value.Value{v}
+		if spread, ok := item.(*Spread); ok {
+			values, err = spread.items(v)
+			if err != nil {
+				return nil, false, err
+			}
+		}
+
+		for _, v := range values {
+			objs = append(objs, v)
+			if IsSchema(ctx) {
+				if value.IsSimpleKind(v.Kind()) {
+					if _, ok := kinds[v.Kind()]; ok {
+						continue
+					} else {
+						kinds[v.Kind()] = struct{}{}
+						v = &value.TypeSchema{
+							KindValue: v.Kind(),
+							Path:      value.GetPath(ctx),
+						}
 					}
+				} else {
+					allSimple = false
+				}
+				if ts, ok := v.(value.Schema); ok {
+					schema = append(schema, ts)
+				} else {
+					return nil, false, value.NewErrPosition(a.Pos,
+						fmt.Errorf("schema for array must contain only schema values, not kind %s [%T]", v.Kind(), v))
 				}
-			} else {
-				allSimple = false
-			}
-			if ts, ok := v.(value.Schema); ok {
-				schema = append(schema, ts)
-			} else {
-				return nil, false, value.NewErrPosition(a.Pos,
-					fmt.Errorf("schema for array must contain only schema values, not kind %s [%T]", v.Kind(), v))
 			}
 		}
 	}
@@ -67,8 +67,23 @@ func declsToFields(decls []ast.Decl) (result []Field, err error) {
 	)
 
 	for _, decl := range decls {
+		if _, ok := decl.(*ast.BadDecl); ok {
+			continue
+		}
+		if let, ok := decl.(*ast.LetClause); ok && let.Pattern != nil {
+			letFields, err := letPatternToFields(let)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			fields = append(fields, letFields...)
+			continue
+		}
 		field, err := declToField(decl)
-		if err != nil {
+		if errors.Is(err, errBadNode) {
+			// declarations left by the parser's error recovery are skipped
+			continue
+		} else if err != nil {
 			errs = append(errs, err)
 			continue
 		}
@@ -84,12 +99,21 @@ func declToField(decl ast.Decl) (ref Field, err error) {
 		var result KeyValue
 		result.Comments = getComments(decl)
 		result.Optional = v.Constraint == token.OPTION
+		result.Override = v.Constraint == token.NOT
 		result.Key, err = labelToKey(v.Label, v.Match != token.NoPos)
 		if err != nil {
 			return &result, err
 		}
 		result.Pos = pos(decl.Pos())
+		result.Attributes, err = attributesToValue(v.Attrs)
+		if err != nil {
+			return &result, err
+		}
 		result.Value, err = exprToExpression(v.Value)
+		if err != nil || len(v.Bases) == 0 {
+			return &result, err
+		}
+		result.Value, err = extendsToExpression(v, result.Value)
 		return &result, err
 	case *ast.EmbedDecl:
 		var result Embedded
@@ -97,6 +121,8 @@ func declToField(decl ast.Decl) (ref Field, err error) {
 		result.Comments = getComments(decl)
 		result.Expression, err = exprToExpression(v.Expr)
 		return &result, err
+	case *ast.Spread:
+		return spreadToExpression(v)
 	case *ast.LetClause:
 		var result KeyValue
 		result.Comments = getComments(decl)
@@ -113,6 +139,125 @@ func declToField(decl ast.Decl) (ref Field, err error) {
 	}
 }
 
+// letPatternToFields returns a local field for each name of a let binding that
+// destructures its value
+func letPatternToFields(let *ast.LetClause) (result []Field, _ error) {
+	pattern, err := patternToPattern(let.Pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	expr, err := exprToExpression(let.Expr)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range pattern.Names() {
+		result = append(result, &KeyValue{
+			Comments: getComments(let),
+			Local:    true,
+			Key: FieldKey{
+				Key: name,
+				Pos: pos(let.Pattern.Pos()),
+			},
+			Pos: pos(let.Pos()),
+			Value: &Destructure{
+				Pattern: pattern,
+				Name:    name,
+				Value:   expr,
+			},
+		})
+	}
+	return result, nil
+}
+
+// patternToPattern converts a destructuring pattern and checks that each name is only
+// bound once
+func patternToPattern(expr ast.Expr) (*Pattern, error) {
+	result, err := patternNode(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]struct{}{}
+	for _, name := range result.Names() {
+		if _, ok := seen[name]; ok {
+			return nil, value.NewErrPosition(posValue(expr.Pos()),
+				fmt.Errorf("duplicate name %s in pattern", name))
+		}
+		seen[name] = struct{}{}
+	}
+	return result, nil
+}
+
+func patternNode(expr ast.Expr) (*Pattern, error) {
+	result := &Pattern{
+		Pos: pos(expr.Pos()),
+	}
+
+	switch n := expr.(type) {
+	case *ast.Ident:
+		name, err := value.Unquote(n.Name)
+		if err != nil {
+			return nil, value.NewErrPosition(posValue(n.Pos()), err)
+		}
+		result.Name = name
+	case *ast.ListLit:
+		result.List = true
+		for _, elt := range n.Elts {
+			item, err := patternNode(elt)
+			if err != nil {
+				return nil, err
+			}
+			result.Items = append(result.Items, item)
+		}
+	case *ast.StructLit:
+		for _, elt := range n.Elts {
+			var (
+				key     string
+				pattern ast.Expr
+				err     error
+			)
+			switch elt := elt.(type) {
+			case *ast.EmbedDecl:
+				key, _, _, err = labelToExpression(elt.Expr.(ast.Label))
+				pattern = elt.Expr
+			case *ast.Field:
+				key, _, _, err = labelToExpression(elt.Label)
+				pattern = elt.Value
+			default:
+				return nil, value.NewErrPosition(posValue(elt.Pos()), fmt.Errorf("invalid struct pattern"))
+			}
+			if err != nil {
+				return nil, err
+			}
+			item, err := patternNode(pattern)
+			if err != nil {
+				return nil, err
+			}
+			result.Fields = append(result.Fields, PatternField{
+				Key:     key,
+				Pattern: item,
+			})
+		}
+	default:
+		return nil, value.NewErrPosition(posValue(expr.Pos()), fmt.Errorf("invalid pattern"))
+	}
+
+	return result, nil
+}
+
+func attributesToValue(attrs []*ast.Attribute) (result value.Attributes, _ error) {
+	for _, attr := range attrs {
+		attribute, err := value.ParseAttribute(attr.Split())
+		if err != nil {
+			return nil, value.NewErrPosition(posValue(attr.Pos()), err)
+		}
+		result = append(result, attribute)
+	}
+	return result, nil
+}
+
 func defaultToExpression(comp *ast.DefaultExpr) (Expression, error) {
 	expr, err := exprToExpression(comp.X)
 	if err != nil {
@@ -191,13 +336,38 @@ func ifToExpression(c *ast.If) (Expression, error) {
 	}, nil
 }
 
+func conditionalToExpression(c *ast.ConditionalExpr) (Expression, error) {
+	condition, err := exprToExpression(c.Condition)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := exprToExpression(c.X)
+	if err != nil {
+		return nil, err
+	}
+
+	elseExpr, err := exprToExpression(c.Y)
+	if err != nil {
+		return nil, err
+	}
+
+	return &If{
+		Pos:       pos(c.If),
+		Comments:  getComments(c),
+		Condition: condition,
+		Value:     value,
+		Else:      elseExpr,
+	}, nil
+}
+
 func listComprehensionToExpression(c *ast.ListComprehension) (Expression, error) {
 	value, err := exprToExpression(c.Value)
 	if err != nil {
 		return nil, err
 	}
 
-	return forClauseToFor(c.Clause, value, false)
+	return forClauseToFor(c.Clause, c.Clauses, value, false)
 }
 
 func forToExpression(c *ast.For) (Expression, error) {
@@ -206,7 +376,7 @@ func forToExpression(c *ast.For) (Expression, error) {
 		return nil, err
 	}
 
-	e, err := forClauseToFor(c.Clause, value, true)
+	e, err := forClauseToFor(c.Clause, c.Clauses, value, true)
 	if err != nil {
 		return nil, err
 	}
@@ -222,12 +392,75 @@ func forToExpression(c *ast.For) (Expression, error) {
 	return e, nil
 }
 
-func forClauseToFor(comp *ast.ForClause, expr Expression, merge bool) (*For, error) {
+func forClauseToFor(comp *ast.ForClause, clauses []ast.Clause, expr Expression, merge bool) (*For, error) {
+	clause, err := forClauseToClause(comp)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &For{
+		Comments:   getComments(comp),
+		Key:        clause.Key,
+		Value:      clause.Value,
+		Pattern:    clause.Pattern,
+		Collection: clause.Collection,
+		Body:       expr,
+		Merge:      merge,
+		Position:   clause.Position,
+	}
+
+	for _, c := range clauses {
+		next, err := clauseToClause(c)
+		if err != nil {
+			return nil, err
+		}
+		result.Clauses = append(result.Clauses, next)
+	}
+
+	return result, nil
+}
+
+func clauseToClause(clause ast.Clause) (Clause, error) {
+	switch c := clause.(type) {
+	case *ast.ForClause:
+		return forClauseToClause(c)
+	case *ast.IfClause:
+		condition, err := exprToExpression(c.Condition)
+		if err != nil {
+			return nil, err
+		}
+		return &IfClause{
+			Condition: condition,
+			Position:  pos(c.Pos()),
+		}, nil
+	case *ast.LetClause:
+		result := &LetClause{}
+		if c.Pattern != nil {
+			pattern, err := patternToPattern(c.Pattern)
+			if err != nil {
+				return nil, err
+			}
+			result.Pattern = pattern
+		} else {
+			name, err := value.Unquote(c.Ident.Name)
+			if err != nil {
+				return nil, value.NewErrPosition(posValue(c.Ident.Pos()), err)
+			}
+			result.Name = name
+		}
+		expr, err := exprToExpression(c.Expr)
+		if err != nil {
+			return nil, err
+		}
+		result.Value = expr
+		return result, nil
+	}
+	return nil, value.NewErrPosition(posValue(clause.Pos()), fmt.Errorf("invalid clause %T", clause))
+}
+
+func forClauseToClause(comp *ast.ForClause) (*ForClause, error) {
 	var (
-		result = &For{
-			Comments: getComments(comp),
-			Body:     expr,
-			Merge:    merge,
+		result = &ForClause{
 			Position: pos(comp.Pos()),
 		}
 		err error
@@ -240,9 +473,16 @@ func forClauseToFor(comp *ast.ForClause, expr Expression, merge bool) (*For, err
 		}
 	}
 
-	result.Value, err = value.Unquote(comp.Value.Name)
-	if err != nil {
-		return nil, value.NewErrPosition(posValue(comp.Value.Pos()), err)
+	if comp.Pattern != nil {
+		result.Pattern, err = patternToPattern(comp.Pattern)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		result.Value, err = value.Unquote(comp.Value.Name)
+		if err != nil {
+			return nil, value.NewErrPosition(posValue(comp.Value.Pos()), err)
+		}
 	}
 
 	result.Collection, err = exprToExpression(comp.Source)
@@ -257,7 +497,7 @@ func basicListToValue(lit *ast.BasicLit) (Expression, error) {
 	switch lit.Kind {
 	case token.NUMBER:
 		return Value{
-			Value: value.Number(lit.Value),
+			Value: value.ParseNumber(lit.Value),
 		}, nil
 	case token.STRING:
 		s, err := value.Unquote(lit.Value)
@@ -310,6 +550,21 @@ func schemaToExpression(s *ast.SchemaLit) (Expression, error) {
 	}, nil
 }
 
+func extendsToExpression(field *ast.Field, value Expression) (Expression, error) {
+	result := &Extends{
+		Pos:   pos(field.Extends),
+		Value: value,
+	}
+	for _, base := range field.Bases {
+		expr, err := exprToExpression(base)
+		if err != nil {
+			return nil, err
+		}
+		result.Bases = append(result.Bases, expr)
+	}
+	return result, nil
+}
+
 func structToExpression(s *ast.StructLit) (*Struct, error) {
 	fields, err := declsToFields(s.Elts)
 	if err != nil {
@@ -334,6 +589,18 @@ func listToExpression(list *ast.ListLit) (Expression, error) {
 	}, nil
 }
 
+func spreadToExpression(spread *ast.Spread) (*Spread, error) {
+	expr, err := exprToExpression(spread.X)
+	if err != nil {
+		return nil, err
+	}
+	return &Spread{
+		Pos:        pos(spread.Ellipsis),
+		Comments:   getComments(spread),
+		Expression: expr,
+	}, nil
+}
+
 func exprsToExpressions(exprs []ast.Expr) (result []Expression, _ error) {
 	var errs []error
 	for _, expr := range exprs {
@@ -381,6 +648,15 @@ func binaryToExpression(bin *ast.BinaryExpr) (Expression, error) {
 		return nil, err
 	}
 
+	if bin.Op == token.COALESCE {
+		return &Coalesce{
+			Comments: getComments(bin),
+			Pos:      pos(bin.OpPos),
+			Left:     left,
+			Right:    right,
+		}, nil
+	}
+
 	return &Op{
 		Comments: getComments(bin),
 		Operator: value.Operator(bin.Op.String()),
@@ -432,6 +708,7 @@ func selectorToExpression(sel *ast.SelectorExpr) (Expression, error) {
 		Pos:      pos(sel.Sel.Pos()),
 		Base:     selExpr,
 		Key:      key,
+		Optional: sel.Optional,
 	}, nil
 }
 
@@ -451,6 +728,7 @@ func indexToExpression(indexExpr *ast.IndexExpr) (Expression, error) {
 		Pos:      pos(indexExpr.Pos()),
 		Base:     base,
 		Index:    index,
+		Optional: indexExpr.Optional,
 	}, nil
 }
 
@@ -552,6 +830,8 @@ func exprToExpression(expr ast.Expr) (Expression, error) {
 		return schemaToExpression(n)
 	case *ast.ListLit:
 		return listToExpression(n)
+	case *ast.Spread:
+		return spreadToExpression(n)
 	case *ast.BinaryExpr:
 		return binaryToExpression(n)
 	case *ast.UnaryExpr:
@@ -570,6 +850,8 @@ func exprToExpression(expr ast.Expr) (Expression, error) {
 		return callToExpression(n)
 	case *ast.If:
 		return ifToExpression(n)
+	case *ast.ConditionalExpr:
+		return conditionalToExpression(n)
 	case *ast.Else:
 		return elseToExpression(n)
 	case *ast.For:
@@ -584,6 +866,8 @@ func exprToExpression(expr ast.Expr) (Expression, error) {
 		return funcToExpression(n)
 	case *ast.Lambda:
 		return lambdaToExpression(n)
+	case *ast.BadExpr:
+		return nil, errBadNode
 	default:
 		return nil, NewErrUnknownError(n)
 	}
@@ -655,6 +939,10 @@ func getComments(node ast.Node) (result Comments) {
 
 		if cg != nil {
 			for _, c := range cg.List {
+				if strings.HasPrefix(c.Text, "/*") {
+					group = append(group, blockCommentLines(c.Text)...)
+					continue
+				}
 				l := strings.TrimLeftFunc(strings.TrimPrefix(c.Text, "//"), unicode.IsSpace)
 				group = append(group, l)
 			}
@@ -664,6 +952,30 @@ func getComments(node ast.Node) (result Comments) {
 	return
 }
 
+// blockCommentLines returns the lines of a /*-style comment without the comment
+// markers, leading whitespace or a leading * on each line.
+func blockCommentLines(text string) (result []string) {
+	text = strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/")
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimLeftFunc(line, unicode.IsSpace)
+		if line == "*" || strings.HasPrefix(line, "* ") {
+			line = strings.TrimLeftFunc(line[1:], unicode.IsSpace)
+		}
+		result = append(result, strings.TrimRightFunc(line, unicode.IsSpace))
+	}
+	for len(result) > 0 && result[0] == "" {
+		result = result[1:]
+	}
+	for len(result) > 0 && result[len(result)-1] == "" {
+		result = result[:len(result)-1]
+	}
+	return result
+}
+
+// errBadNode is returned when building an ast.BadExpr from a partial AST produced by
+// parser.Tolerant. The declaration containing it is skipped.
+var errBadNode = fmt.Errorf("invalid syntax")
+
 type ErrUnknownError struct {
 	Node ast.Node
 }
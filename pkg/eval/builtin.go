@@ -59,9 +59,10 @@ func init() {
 	ctx := context.Background()
 
 	data := statics()
-	data["len"] = NativeFuncValue(Len)
+	data["len"] = NativeSchemaFuncValue(Len)
 	data["keys"] = NativeFuncValue(Keys)
 	data["enum"] = NativeFuncValue(Enum)
+	data["unique"] = NativeSchemaFuncValue(UniqueItems)
 	data["int"] = Int()
 	data["any"] = Any(data)
 	data["std"] = addStd(ctx, data)
@@ -73,6 +74,13 @@ func init() {
 
 type nativeCallable struct {
 	f NativeFunc
+	// schemaArgs is set if the arguments are evaluated as schema when the function is
+	// called in a schema
+	schemaArgs bool
+}
+
+func (n nativeCallable) SchemaArguments() bool {
+	return n.schemaArgs
 }
 
 func (n nativeCallable) Eq(right value.Value) (value.Value, error) {
@@ -114,3 +122,18 @@ func NativeFuncValue(f func(context.Context, []value.Value) (value.Value, bool,
 		f: f,
 	}
 }
+
+// NativeSchemaFuncValue is a native function whose arguments are schema when it is called
+// in a schema, such as len in len([string]) > 0
+func NativeSchemaFuncValue(f func(context.Context, []value.Value) (value.Value, bool, error)) value.Value {
+	return nativeCallable{
+		f:          f,
+		schemaArgs: true,
+	}
+}
+
+// SchemaArguments is implemented by functions that take schema arguments when they are
+// called in a schema
+type SchemaArguments interface {
+	SchemaArguments() bool
+}
@@ -0,0 +1,453 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/acorn-io/aml/pkg/value"
+)
+
+// callFunc calls fn with the given positional arguments. If fn is null the first
+// argument is returned unchanged.
+func callFunc(ctx context.Context, fn value.Value, args ...value.Value) (value.Value, bool, error) {
+	if fn.Kind() == value.NullKind {
+		return args[0], true, nil
+	}
+	var callArgs []value.CallArgument
+	for _, arg := range args {
+		callArgs = append(callArgs, value.CallArgument{
+			Positional: true,
+			Value:      arg,
+		})
+	}
+	return value.Call(ctx, fn, callArgs...)
+}
+
+// callPredicate calls fn and converts the result to a bool. If fn is null the
+// argument itself must be a bool.
+func callPredicate(ctx context.Context, fn, arg value.Value) (bool, bool, error) {
+	ret, ok, err := callFunc(ctx, fn, arg)
+	if err != nil || !ok {
+		return false, ok, err
+	}
+	b, err := value.ToBool(ret)
+	return b, true, err
+}
+
+func Map(ctx context.Context, args []value.Value) (value.Value, bool, error) {
+	arr, err := value.ToValueArray(args[0])
+	if err != nil {
+		return nil, false, err
+	}
+
+	result := value.Array{}
+	for _, item := range arr {
+		ret, ok, err := callFunc(ctx, args[1], item)
+		if err != nil || !ok {
+			return nil, ok, err
+		}
+		result = append(result, ret)
+	}
+
+	return result, true, nil
+}
+
+func Filter(ctx context.Context, args []value.Value) (value.Value, bool, error) {
+	arr, err := value.ToValueArray(args[0])
+	if err != nil {
+		return nil, false, err
+	}
+
+	result := value.Array{}
+	for _, item := range arr {
+		keep, ok, err := callPredicate(ctx, args[1], item)
+		if err != nil || !ok {
+			return nil, ok, err
+		} else if keep {
+			result = append(result, item)
+		}
+	}
+
+	return result, true, nil
+}
+
+func Reduce(ctx context.Context, args []value.Value) (value.Value, bool, error) {
+	arr, err := value.ToValueArray(args[0])
+	if err != nil {
+		return nil, false, err
+	}
+
+	acc := args[2]
+	for _, item := range arr {
+		ret, ok, err := value.Call(ctx, args[1], value.CallArgument{
+			Positional: true,
+			Value:      acc,
+		}, value.CallArgument{
+			Positional: true,
+			Value:      item,
+		})
+		if err != nil || !ok {
+			return nil, ok, err
+		}
+		acc = ret
+	}
+
+	return acc, true, nil
+}
+
+func flatten(v value.Value, depth int64) (value.Array, error) {
+	arr, err := value.ToValueArray(v)
+	if err != nil {
+		return nil, err
+	}
+
+	result := value.Array{}
+	for _, item := range arr {
+		if item.Kind() != value.ArrayKind || depth == 0 {
+			result = append(result, item)
+			continue
+		}
+		items, err := flatten(item, depth-1)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, items...)
+	}
+
+	return result, nil
+}
+
+func Flatten(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	depth, err := value.ToInt(args[1])
+	if err != nil {
+		return nil, false, err
+	}
+
+	result, err := flatten(args[0], depth)
+	if err != nil {
+		return nil, false, err
+	}
+	return result, true, nil
+}
+
+func Unique(ctx context.Context, args []value.Value) (value.Value, bool, error) {
+	arr, err := value.ToValueArray(args[0])
+	if err != nil {
+		return nil, false, err
+	}
+
+	var (
+		result value.Array
+		keys   uniqueKeys
+	)
+	for _, item := range arr {
+		key, ok, err := callFunc(ctx, args[1], item)
+		if err != nil || !ok {
+			return nil, ok, err
+		}
+
+		added, err := keys.add(key)
+		if err != nil {
+			return nil, false, err
+		}
+		if added {
+			result = append(result, item)
+		}
+	}
+
+	if result == nil {
+		result = value.Array{}
+	}
+	return result, true, nil
+}
+
+// uniqueKeys is a set of values. Strings are looked up in a map, other values are
+// compared with the values of the same kind.
+type uniqueKeys struct {
+	strings map[string]struct{}
+	others  map[value.Kind][]value.Value
+}
+
+// add adds v to the set and returns true if it was not already in it
+func (u *uniqueKeys) add(v value.Value) (bool, error) {
+	if v.Kind() == value.StringKind {
+		s, err := value.ToString(v)
+		if err != nil {
+			return false, err
+		}
+		if _, ok := u.strings[s]; ok {
+			return false, nil
+		}
+		if u.strings == nil {
+			u.strings = map[string]struct{}{}
+		}
+		u.strings[s] = struct{}{}
+		return true, nil
+	}
+
+	for _, seen := range u.others[v.Kind()] {
+		eq, err := value.Eq(seen, v)
+		if err != nil {
+			return false, err
+		}
+		if b, err := value.ToBool(eq); err != nil {
+			return false, err
+		} else if b {
+			return false, nil
+		}
+	}
+	if u.others == nil {
+		u.others = map[value.Kind][]value.Value{}
+	}
+	u.others[v.Kind()] = append(u.others[v.Kind()], v)
+	return true, nil
+}
+
+func GroupBy(ctx context.Context, args []value.Value) (value.Value, bool, error) {
+	arr, err := value.ToValueArray(args[0])
+	if err != nil {
+		return nil, false, err
+	}
+
+	result := &value.Object{}
+	groups := map[string]int{}
+	for _, item := range arr {
+		ret, ok, err := callFunc(ctx, args[1], item)
+		if err != nil || !ok {
+			return nil, ok, err
+		}
+
+		key, err := value.ToString(ret)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid groupBy key: %w", err)
+		}
+
+		if i, ok := groups[key]; ok {
+			result.Entries[i].Value = append(result.Entries[i].Value.(value.Array), item)
+		} else {
+			groups[key] = len(result.Entries)
+			result.Entries = append(result.Entries, value.Entry{
+				Key:   key,
+				Value: value.Array{item},
+			})
+		}
+	}
+
+	return result, true, nil
+}
+
+func Zip(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	var arrays [][]value.Value
+	for _, arg := range args {
+		arr, err := value.ToValueArray(arg)
+		if err != nil {
+			return nil, false, err
+		}
+		arrays = append(arrays, arr)
+	}
+
+	result := value.Array{}
+	for i := 0; ; i++ {
+		var tuple value.Array
+		for _, arr := range arrays {
+			if i >= len(arr) {
+				return result, true, nil
+			}
+			tuple = append(tuple, arr[i])
+		}
+		result = append(result, tuple)
+	}
+}
+
+func Chunk(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	arr, err := value.ToValueArray(args[0])
+	if err != nil {
+		return nil, false, err
+	}
+
+	size, err := value.ToInt(args[1])
+	if err != nil {
+		return nil, false, err
+	}
+	if size <= 0 {
+		return nil, false, fmt.Errorf("invalid chunk size %d, must be greater than zero", size)
+	}
+
+	result := value.Array{}
+	for i := 0; i < len(arr); i += int(size) {
+		end := i + int(size)
+		if end > len(arr) {
+			end = len(arr)
+		}
+		result = append(result, value.Array(arr[i:end]))
+	}
+
+	return result, true, nil
+}
+
+func AnyMatch(ctx context.Context, args []value.Value) (value.Value, bool, error) {
+	arr, err := value.ToValueArray(args[0])
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, item := range arr {
+		match, ok, err := callPredicate(ctx, args[1], item)
+		if err != nil || !ok {
+			return nil, ok, err
+		} else if match {
+			return value.True, true, nil
+		}
+	}
+
+	return value.False, true, nil
+}
+
+func AllMatch(ctx context.Context, args []value.Value) (value.Value, bool, error) {
+	arr, err := value.ToValueArray(args[0])
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, item := range arr {
+		match, ok, err := callPredicate(ctx, args[1], item)
+		if err != nil || !ok {
+			return nil, ok, err
+		} else if !match {
+			return value.False, true, nil
+		}
+	}
+
+	return value.True, true, nil
+}
+
+func Find(ctx context.Context, args []value.Value) (value.Value, bool, error) {
+	arr, err := value.ToValueArray(args[0])
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, item := range arr {
+		match, ok, err := callPredicate(ctx, args[1], item)
+		if err != nil || !ok {
+			return nil, ok, err
+		} else if match {
+			return item, true, nil
+		}
+	}
+
+	return value.NewNull(), true, nil
+}
+
+// filterKeys returns the entries of the object whose key presence in keys equals keep
+func filterKeys(obj, keys value.Value, keep bool) (value.Value, bool, error) {
+	keyValues, err := value.ToValueArray(keys)
+	if err != nil {
+		return nil, false, err
+	}
+
+	set := map[string]bool{}
+	for _, key := range keyValues {
+		s, err := value.ToString(key)
+		if err != nil {
+			return nil, false, err
+		}
+		set[s] = true
+	}
+
+	objKeys, err := value.Keys(obj)
+	if err != nil {
+		return nil, false, err
+	}
+
+	result := &value.Object{}
+	for _, key := range objKeys {
+		if set[key] != keep {
+			continue
+		}
+		v, _, err := value.Lookup(obj, value.NewValue(key))
+		if err != nil {
+			return nil, false, err
+		}
+		result.Entries = append(result.Entries, value.Entry{
+			Key:   key,
+			Value: v,
+		})
+	}
+
+	return result, true, nil
+}
+
+func Pick(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	return filterKeys(args[0], args[1], true)
+}
+
+func Omit(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	return filterKeys(args[0], args[1], false)
+}
+
+func Entries(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	keys, err := value.Keys(args[0])
+	if err != nil {
+		return nil, false, err
+	}
+
+	result := value.Array{}
+	for _, key := range keys {
+		v, _, err := value.Lookup(args[0], value.NewValue(key))
+		if err != nil {
+			return nil, false, err
+		}
+		result = append(result, &value.Object{
+			Entries: []value.Entry{
+				{Key: "key", Value: value.NewValue(key)},
+				{Key: "value", Value: v},
+			},
+		})
+	}
+
+	return result, true, nil
+}
+
+func FromEntries(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	entries, err := value.ToValueArray(args[0])
+	if err != nil {
+		return nil, false, err
+	}
+
+	result := &value.Object{}
+	index := map[string]int{}
+	for _, entry := range entries {
+		key, ok, err := value.Lookup(entry, value.NewValue("key"))
+		if err != nil {
+			return nil, false, err
+		} else if !ok {
+			return nil, false, fmt.Errorf("entry %s is missing the key field", entry)
+		}
+
+		keyString, err := value.ToString(key)
+		if err != nil {
+			return nil, false, err
+		}
+
+		v, ok, err := value.Lookup(entry, value.NewValue("value"))
+		if err != nil {
+			return nil, false, err
+		} else if !ok {
+			return nil, false, fmt.Errorf("entry %s is missing the value field", entry)
+		}
+
+		// later entries win, as they would with duplicate keys in a map
+		if i, ok := index[keyString]; ok {
+			result.Entries[i].Value = v
+			continue
+		}
+		index[keyString] = len(result.Entries)
+		result.Entries = append(result.Entries, value.Entry{
+			Key:   keyString,
+			Value: v,
+		})
+	}
+
+	return result, true, nil
+}
@@ -13,9 +13,11 @@ import (
 	"log"
 	"net"
 	"path"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/acorn-io/aml/pkg/value"
@@ -25,47 +27,89 @@ import (
 var (
 	DebugEnabled = true
 	nativeFuncs  = map[string]any{
-		"atoi":          NativeFuncValue(Atoi),
-		"range":         NativeFuncValue(Range),
-		"fromYAML":      NativeFuncValue(FromYAML),
-		"toYAML":        NativeFuncValue(ToYAML),
-		"sha1sum":       NativeFuncValue(Sha1sum),
-		"sha256sum":     NativeFuncValue(Sha256sum),
-		"sha512sum":     NativeFuncValue(Sha512sum),
-		"base64":        NativeFuncValue(Base64),
-		"base64decode":  NativeFuncValue(Base64Decode),
-		"toHex":         NativeFuncValue(ToHex),
-		"fromHex":       NativeFuncValue(FromHex),
-		"toJSON":        NativeFuncValue(ToJSON),
-		"fromJSON":      NativeFuncValue(FromJSON),
-		"splitHostPort": NativeFuncValue(SplitHostPort),
-		"joinHostPort":  NativeFuncValue(JoinHostPort),
-		"cut":           NativeFuncValue(Cut),
-		"pathJoin":      NativeFuncValue(PathJoin),
-		"dirname":       NativeFuncValue(Dirname),
-		"basename":      NativeFuncValue(Basename),
-		"fileExt":       NativeFuncValue(FileExt),
-		"toTitle":       NativeFuncValue(ToTitle),
-		"isA":           NativeFuncValue(IsA),
-		"split":         NativeFuncValue(Split),
-		"join":          NativeFuncValue(Join),
-		"endsWith":      NativeFuncValue(EndsWith),
-		"startsWith":    NativeFuncValue(StartsWith),
-		"toUpper":       NativeFuncValue(ToUpper),
-		"toLower":       NativeFuncValue(ToLower),
-		"trimSuffix":    NativeFuncValue(TrimSuffix),
-		"trimPrefix":    NativeFuncValue(TrimPrefix),
-		"trim":          NativeFuncValue(Trim),
-		"replace":       NativeFuncValue(Replace),
-		"indexOf":       NativeFuncValue(IndexOf),
-		"merge":         NativeFuncValue(Merge),
-		"sort":          NativeFuncValue(Sort),
-		"mod":           NativeFuncValue(Mod),
-		"error":         NativeFuncValue(Error),
-		"debug":         NativeFuncValue(Debug),
-		"catch":         NativeFuncValue(Catch),
-		"contains":      NativeFuncValue(Contains),
-		"describe":      NativeFuncValue(Describe),
+		"atoi":           NativeFuncValue(Atoi),
+		"range":          NativeFuncValue(Range),
+		"fromYAML":       NativeFuncValue(FromYAML),
+		"toYAML":         NativeFuncValue(ToYAML),
+		"sha1sum":        NativeFuncValue(Sha1sum),
+		"sha256sum":      NativeFuncValue(Sha256sum),
+		"sha512sum":      NativeFuncValue(Sha512sum),
+		"base64":         NativeFuncValue(Base64),
+		"base64decode":   NativeFuncValue(Base64Decode),
+		"toHex":          NativeFuncValue(ToHex),
+		"fromHex":        NativeFuncValue(FromHex),
+		"toJSON":         NativeFuncValue(ToJSON),
+		"fromJSON":       NativeFuncValue(FromJSON),
+		"splitHostPort":  NativeFuncValue(SplitHostPort),
+		"joinHostPort":   NativeFuncValue(JoinHostPort),
+		"cut":            NativeFuncValue(Cut),
+		"pathJoin":       NativeFuncValue(PathJoin),
+		"dirname":        NativeFuncValue(Dirname),
+		"basename":       NativeFuncValue(Basename),
+		"fileExt":        NativeFuncValue(FileExt),
+		"toTitle":        NativeFuncValue(ToTitle),
+		"isA":            NativeFuncValue(IsA),
+		"split":          NativeFuncValue(Split),
+		"regexMatch":     NativeFuncValue(RegexMatch),
+		"regexFind":      NativeFuncValue(RegexFind),
+		"regexFindAll":   NativeFuncValue(RegexFindAll),
+		"regexReplace":   NativeFuncValue(RegexReplace),
+		"regexSplit":     NativeFuncValue(RegexSplit),
+		"regexQuoteMeta": NativeFuncValue(RegexQuoteMeta),
+		"join":           NativeFuncValue(Join),
+		"endsWith":       NativeFuncValue(EndsWith),
+		"startsWith":     NativeFuncValue(StartsWith),
+		"toUpper":        NativeFuncValue(ToUpper),
+		"toLower":        NativeFuncValue(ToLower),
+		"trimSuffix":     NativeFuncValue(TrimSuffix),
+		"trimPrefix":     NativeFuncValue(TrimPrefix),
+		"trim":           NativeFuncValue(Trim),
+		"replace":        NativeFuncValue(Replace),
+		"indexOf":        NativeFuncValue(IndexOf),
+		"merge":          NativeFuncValue(Merge),
+		"sort":           NativeFuncValue(Sort),
+		"map":            NativeFuncValue(Map),
+		"filter":         NativeFuncValue(Filter),
+		"reduce":         NativeFuncValue(Reduce),
+		"flatten":        NativeFuncValue(Flatten),
+		"unique":         NativeFuncValue(Unique),
+		"groupBy":        NativeFuncValue(GroupBy),
+		"zip":            NativeFuncValue(Zip),
+		"chunk":          NativeFuncValue(Chunk),
+		"anyOf":          NativeFuncValue(AnyMatch),
+		"allOf":          NativeFuncValue(AllMatch),
+		"find":           NativeFuncValue(Find),
+		"pick":           NativeFuncValue(Pick),
+		"omit":           NativeFuncValue(Omit),
+		"entries":        NativeFuncValue(Entries),
+		"fromEntries":    NativeFuncValue(FromEntries),
+		"mod":            NativeFuncValue(Mod),
+		"error":          NativeFuncValue(Error),
+		"debug":          NativeFuncValue(Debug),
+		"catch":          NativeFuncValue(Catch),
+		"contains":       NativeFuncValue(Contains),
+		"describe":       NativeFuncValue(Describe),
+		"template":       NativeFuncValue(Template),
+		"toUnit":         NativeFuncValue(ToUnit),
+		"min":            NativeFuncValue(Min),
+		"max":            NativeFuncValue(Max),
+		"abs":            NativeFuncValue(Abs),
+		"floor":          NativeFuncValue(Floor),
+		"ceil":           NativeFuncValue(Ceil),
+		"round":          NativeFuncValue(Round),
+		"pow":            NativeFuncValue(Pow),
+		"sqrt":           NativeFuncValue(Sqrt),
+		"log":            NativeFuncValue(Log),
+		"sum":            NativeFuncValue(Sum),
+		"avg":            NativeFuncValue(Avg),
+		"div":            NativeFuncValue(IntDiv),
+		"clamp":          NativeFuncValue(Clamp),
+		"bitAnd":         NativeFuncValue(BitAnd),
+		"bitOr":          NativeFuncValue(BitOr),
+		"bitXor":         NativeFuncValue(BitXor),
+		"bitNot":         NativeFuncValue(BitNot),
+		"shiftLeft":      NativeFuncValue(ShiftLeft),
+		"shiftRight":     NativeFuncValue(ShiftRight),
 	}
 )
 
@@ -84,6 +128,35 @@ func Mod(_ context.Context, args []value.Value) (value.Value, bool, error) {
 	return value.NewValue(ret), true, nil
 }
 
+func ToUnit(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	unit, err := value.ToString(args[1])
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch v := args[0].(type) {
+	case value.Duration:
+		ret, err := v.ToUnit(unit)
+		return ret, true, err
+	case value.Quantity:
+		ret, err := v.ToUnit(unit)
+		return ret, true, err
+	case value.Number:
+		// plain numbers are seconds when converted to a duration unit
+		if _, err := value.Duration("1" + unit).ToDuration(); err == nil {
+			seconds, err := v.ToFloat()
+			if err != nil {
+				return nil, false, err
+			}
+			ret, err := value.NewDuration(time.Duration(seconds * float64(time.Second))).ToUnit(unit)
+			return ret, true, err
+		}
+		ret, err := value.Quantity(v).ToUnit(unit)
+		return ret, true, err
+	}
+	return nil, false, fmt.Errorf("can not convert kind %s to unit %s", args[0].Kind(), unit)
+}
+
 func Len(_ context.Context, args []value.Value) (value.Value, bool, error) {
 	v, err := value.Len(args[0])
 	return v, true, err
@@ -185,13 +258,26 @@ func Sort(ctx context.Context, args []value.Value) (value.Value, bool, error) {
 	return value.NewValue(arr), true, errors.Join(errs...)
 }
 
-func mergeValue(left, right value.Value) (value.Value, error) {
+// merger merges the values of std.merge. Arrays are merged with the strategy of the
+// @merge attribute of their field in the schema, or else with the strategy of arrays.
+// This is the only place @merge is read, + and value.MergeObjects always replace arrays.
+type merger struct {
+	arrays value.ArrayMerge
+}
+
+func (m merger) mergeValue(left, right value.Value, arrays value.ArrayMerge, schema value.Value) (value.Value, error) {
 	if left == nil {
 		return right, nil
 	} else if right == nil {
 		return left, nil
 	}
 
+	if left.Kind() == value.ArrayKind && right.Kind() == value.ArrayKind {
+		return arrays.Merge(left, right, func(left, right value.Value) (value.Value, error) {
+			return m.mergeValue(left, right, m.arrays, itemSchema(schema))
+		})
+	}
+
 	if left.Kind() != value.ObjectKind || right.Kind() != value.ObjectKind {
 		return right, nil
 	}
@@ -217,7 +303,12 @@ func mergeValue(left, right value.Value) (value.Value, error) {
 			rightValue = nil
 		}
 
-		merged[leftKey], err = mergeValue(leftValue, rightValue)
+		arrays, fieldSchema, err := m.field(schema, leftKey)
+		if err != nil {
+			return nil, err
+		}
+
+		merged[leftKey], err = m.mergeValue(leftValue, rightValue, arrays, fieldSchema)
 		if err != nil {
 			return nil, err
 		}
@@ -244,9 +335,69 @@ func mergeValue(left, right value.Value) (value.Value, error) {
 	return value.NewValue(merged), nil
 }
 
+// field returns the array merge strategy and the schema of the field key of the
+// object schema
+func (m merger) field(schema value.Value, key string) (value.ArrayMerge, value.Value, error) {
+	ts, ok := schema.(*value.TypeSchema)
+	if !ok || ts.Object == nil {
+		return m.arrays, nil, nil
+	}
+
+	for _, field := range ts.Object.Fields {
+		if field.Match || field.Key != key {
+			continue
+		}
+		attr, ok := field.Attributes.Get("merge")
+		if !ok {
+			return m.arrays, field.Schema, nil
+		}
+		if len(attr.Args) != 1 || attr.Args[0].Key != "" {
+			return m.arrays, nil, fmt.Errorf("invalid attribute @merge of field %s, expected one argument of replace, append or mergeByKey:<key>", key)
+		}
+		arrays, err := value.ParseArrayMerge(attr.Args[0].Value)
+		if err != nil {
+			return m.arrays, nil, fmt.Errorf("invalid attribute @merge of field %s: %w", key, err)
+		}
+		return arrays, field.Schema, nil
+	}
+
+	return m.arrays, nil, nil
+}
+
+// itemSchema returns the schema of the items of an array schema with a single item type
+func itemSchema(schema value.Value) value.Value {
+	ts, ok := schema.(*value.TypeSchema)
+	if !ok || ts.Array == nil || len(ts.Array.Valid) != 1 {
+		return nil
+	}
+	return ts.Array.Valid[0]
+}
+
 func Merge(_ context.Context, args []value.Value) (value.Value, bool, error) {
-	left, right := args[0], args[1]
-	merged, err := mergeValue(left, right)
+	left, right, options := args[0], args[1], args[2]
+
+	var arrays value.ArrayMerge
+	if arraysValue, ok, err := value.Lookup(options, value.NewValue("arrays")); err != nil {
+		return nil, false, err
+	} else if ok {
+		s, err := value.ToString(arraysValue)
+		if err != nil {
+			return nil, false, err
+		}
+		arrays, err = value.ParseArrayMerge(s)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	schema, ok, err := value.Lookup(options, value.NewValue("strategies"))
+	if err != nil {
+		return nil, false, err
+	} else if !ok || schema.Kind() == value.NullKind {
+		schema = nil
+	}
+
+	merged, err := merger{arrays: arrays}.mergeValue(left, right, arrays, schema)
 	return merged, true, err
 }
 
@@ -552,6 +703,103 @@ func JoinHostPort(_ context.Context, args []value.Value) (value.Value, bool, err
 	return value.NewValue(result), true, nil
 }
 
+func regexArgs(args []value.Value) (string, *regexp.Regexp, error) {
+	str, err := value.ToString(args[0])
+	if err != nil {
+		return "", nil, err
+	}
+
+	pattern, err := value.ToString(args[1])
+	if err != nil {
+		return "", nil, err
+	}
+
+	re, err := value.CompileRegexp(pattern)
+	return str, re, err
+}
+
+func stringsToArray(strs []string) value.Array {
+	result := value.Array{}
+	for _, s := range strs {
+		result = append(result, value.NewValue(s))
+	}
+	return result
+}
+
+func RegexMatch(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	str, re, err := regexArgs(args)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return value.NewValue(re.MatchString(str)), true, nil
+}
+
+func RegexFind(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	str, re, err := regexArgs(args)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return stringsToArray(re.FindStringSubmatch(str)), true, nil
+}
+
+func RegexFindAll(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	str, re, err := regexArgs(args)
+	if err != nil {
+		return nil, false, err
+	}
+
+	count, err := value.ToInt(args[2])
+	if err != nil {
+		return nil, false, err
+	}
+
+	result := value.Array{}
+	for _, match := range re.FindAllStringSubmatch(str, int(count)) {
+		result = append(result, stringsToArray(match))
+	}
+
+	return result, true, nil
+}
+
+func RegexReplace(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	str, re, err := regexArgs(args)
+	if err != nil {
+		return nil, false, err
+	}
+
+	replacement, err := value.ToString(args[2])
+	if err != nil {
+		return nil, false, err
+	}
+
+	return value.NewValue(re.ReplaceAllString(str, replacement)), true, nil
+}
+
+func RegexSplit(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	str, re, err := regexArgs(args)
+	if err != nil {
+		return nil, false, err
+	}
+
+	count, err := value.ToInt(args[2])
+	if err != nil {
+		return nil, false, err
+	}
+
+	return stringsToArray(re.Split(str, int(count))), true, nil
+}
+
+func RegexQuoteMeta(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	str, err := value.ToString(args[0])
+	if err != nil {
+		return nil, false, err
+	}
+
+	return value.NewValue(regexp.QuoteMeta(str)), true, nil
+}
+
 func SplitHostPort(_ context.Context, args []value.Value) (value.Value, bool, error) {
 	s, err := value.ToString(args[0])
 	if err != nil {
@@ -774,6 +1022,25 @@ func Enum(_ context.Context, args []value.Value) (value.Value, bool, error) {
 	return result, true, nil
 }
 
+// UniqueItems returns a copy of an array schema that requires the items of the array to
+// be unique, as in unique([string])
+func UniqueItems(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	if len(args) != 1 {
+		return nil, false, fmt.Errorf("unique expects one array schema argument, got %d arguments", len(args))
+	}
+
+	ts, ok := args[0].(*value.TypeSchema)
+	if !ok || ts.Array == nil {
+		return nil, false, fmt.Errorf("unique expects an array schema, got %s", value.TargetKind(args[0]))
+	}
+
+	result := *ts
+	array := *ts.Array
+	array.Unique = true
+	result.Array = &array
+	return &result, true, nil
+}
+
 func Contains(ctx context.Context, args []value.Value) (value.Value, bool, error) {
 	collection := args[0]
 	if collection.Kind() == value.ObjectKind {
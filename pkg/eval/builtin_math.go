@@ -0,0 +1,257 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/acorn-io/aml/pkg/value"
+)
+
+func toNumbers(v value.Value) ([]value.Value, error) {
+	values, err := value.ToValueArray(v)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range values {
+		if v.Kind() != value.NumberKind {
+			return nil, fmt.Errorf("expected an array of numbers, found kind %s", v.Kind())
+		}
+	}
+	return values, nil
+}
+
+// minMax returns the first value of the array for which better(value, current) holds
+// against every other value
+func minMax(v value.Value, better func(left, right value.Value) (value.Value, error)) (value.Value, bool, error) {
+	values, err := toNumbers(v)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(values) == 0 {
+		return nil, false, fmt.Errorf("can not find min or max of an empty array")
+	}
+
+	result := values[0]
+	for _, v := range values[1:] {
+		if ok, err := compare(better, v, result); err != nil {
+			return nil, false, err
+		} else if ok {
+			result = v
+		}
+	}
+	return result, true, nil
+}
+
+// compare returns the result of the comparison op of left and right
+func compare(op func(left, right value.Value) (value.Value, error), left, right value.Value) (bool, error) {
+	v, err := op(left, right)
+	if err != nil {
+		return false, err
+	}
+	return value.ToBool(v)
+}
+
+func Min(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	return minMax(args[0], value.Lt)
+}
+
+func Max(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	return minMax(args[0], value.Gt)
+}
+
+func Abs(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	lt, err := value.Lt(args[0], value.NewValue(0))
+	if err != nil {
+		return nil, false, err
+	}
+	if !isTrue(lt, nil) {
+		return args[0], true, nil
+	}
+	ret, err := value.UnaryOperation(value.SubOp, args[0])
+	return ret, true, err
+}
+
+func floatFunc(v value.Value, f func(float64) float64) (value.Value, bool, error) {
+	n, err := value.ToFloat(v)
+	if err != nil {
+		return nil, false, err
+	}
+	ret := f(n)
+	if math.IsNaN(ret) || math.IsInf(ret, 0) {
+		return nil, false, fmt.Errorf("result of %s is not a finite number", v)
+	}
+	if ret == math.Trunc(ret) && math.Abs(ret) < math.MaxInt64 {
+		return value.NewValue(int64(ret)), true, nil
+	}
+	return value.NewValue(ret), true, nil
+}
+
+func Floor(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	return floatFunc(args[0], math.Floor)
+}
+
+func Ceil(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	return floatFunc(args[0], math.Ceil)
+}
+
+func Round(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	places, err := value.ToInt(args[1])
+	if err != nil {
+		return nil, false, err
+	}
+	scale := math.Pow10(int(places))
+	return floatFunc(args[0], func(f float64) float64 {
+		return math.Round(f*scale) / scale
+	})
+}
+
+func Pow(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	exp, err := value.ToFloat(args[1])
+	if err != nil {
+		return nil, false, err
+	}
+	return floatFunc(args[0], func(f float64) float64 {
+		return math.Pow(f, exp)
+	})
+}
+
+func Sqrt(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	return floatFunc(args[0], math.Sqrt)
+}
+
+func Log(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	if args[1].Kind() == value.NullKind {
+		return floatFunc(args[0], math.Log)
+	}
+	base, err := value.ToFloat(args[1])
+	if err != nil {
+		return nil, false, err
+	}
+	return floatFunc(args[0], func(f float64) float64 {
+		return math.Log(f) / math.Log(base)
+	})
+}
+
+func Sum(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	values, err := toNumbers(args[0])
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(values) == 0 {
+		return value.NewValue(0), true, nil
+	}
+
+	result := values[0]
+	for _, v := range values[1:] {
+		result, err = value.Add(result, v)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	return result, true, nil
+}
+
+func Avg(ctx context.Context, args []value.Value) (value.Value, bool, error) {
+	sum, ok, err := Sum(ctx, args)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+	values, err := value.ToValueArray(args[0])
+	if err != nil {
+		return nil, false, err
+	}
+	if len(values) == 0 {
+		return nil, false, fmt.Errorf("can not average an empty array")
+	}
+	ret, err := value.Div(sum, value.NewValue(len(values)))
+	return ret, true, err
+}
+
+func IntDiv(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	left, right, err := intArgs(args)
+	if err != nil {
+		return nil, false, err
+	}
+	if right == 0 {
+		return nil, false, fmt.Errorf("integer divide by zero")
+	}
+	return value.NewValue(left / right), true, nil
+}
+
+func Clamp(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	v, low, high := args[0], args[1], args[2]
+	if invalid, err := compare(value.Gt, low, high); err != nil {
+		return nil, false, err
+	} else if invalid {
+		return nil, false, fmt.Errorf("invalid clamp range, min %s is greater than max %s", low, high)
+	}
+	if below, err := compare(value.Lt, v, low); err != nil {
+		return nil, false, err
+	} else if below {
+		return low, true, nil
+	}
+	if above, err := compare(value.Gt, v, high); err != nil {
+		return nil, false, err
+	} else if above {
+		return high, true, nil
+	}
+	return v, true, nil
+}
+
+func intArgs(args []value.Value) (int64, int64, error) {
+	left, err := value.ToInt(args[0])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	right, err := value.ToInt(args[1])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return left, right, nil
+}
+
+func BitAnd(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	left, right, err := intArgs(args)
+	return value.NewValue(left & right), err == nil, err
+}
+
+func BitOr(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	left, right, err := intArgs(args)
+	return value.NewValue(left | right), err == nil, err
+}
+
+func BitXor(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	left, right, err := intArgs(args)
+	return value.NewValue(left ^ right), err == nil, err
+}
+
+func BitNot(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	v, err := value.ToInt(args[0])
+	return value.NewValue(^v), err == nil, err
+}
+
+func ShiftLeft(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	left, right, err := intArgs(args)
+	if err != nil {
+		return nil, false, err
+	}
+	if right < 0 {
+		return nil, false, fmt.Errorf("negative shift count %d", right)
+	}
+	return value.NewValue(left << right), true, nil
+}
+
+func ShiftRight(_ context.Context, args []value.Value) (value.Value, bool, error) {
+	left, right, err := intArgs(args)
+	if err != nil {
+		return nil, false, err
+	}
+	if right < 0 {
+		return nil, false, fmt.Errorf("negative shift count %d", right)
+	}
+	return value.NewValue(left >> right), true, nil
+}
@@ -0,0 +1,115 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/acorn-io/aml/pkg/value"
+	"gopkg.in/yaml.v3"
+)
+
+const templateName = "template"
+
+var (
+	templateErrPrefix = regexp.MustCompile(`^template: ` + templateName + `:(\d+(:\d+)?): (executing "` + templateName + `" at <[^>]*>: )?`)
+	templateFuncs     = template.FuncMap{
+		"join": func(items []any, sep string) string {
+			strs := make([]string, 0, len(items))
+			for _, item := range items {
+				strs = append(strs, fmt.Sprint(item))
+			}
+			return strings.Join(strs, sep)
+		},
+		"toUpper": strings.ToUpper,
+		"toLower": strings.ToLower,
+		"trim":    strings.TrimSpace,
+		"quote": func(s any) string {
+			return fmt.Sprintf("%q", fmt.Sprint(s))
+		},
+		"indent": func(spaces int, s string) string {
+			pad := strings.Repeat(" ", spaces)
+			return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+		},
+		"toJSON": func(v any) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+		"toYAML": func(v any) (string, error) {
+			data, err := yaml.Marshal(v)
+			return strings.TrimSuffix(string(data), "\n"), err
+		},
+	}
+)
+
+// toTemplateData converts a native value to plain Go types so that numbers compare
+// and format naturally in templates. Quantities and durations become strings.
+func toTemplateData(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for k, item := range v {
+			result[k] = toTemplateData(item)
+		}
+		return result
+	case []any:
+		result := make([]any, 0, len(v))
+		for _, item := range v {
+			result = append(result, toTemplateData(item))
+		}
+		return result
+	case value.Number:
+		if i, err := v.ToInt(); err == nil {
+			return i
+		}
+		if f, err := v.ToFloat(); err == nil {
+			return f
+		}
+		return string(v)
+	case value.Quantity:
+		return string(v)
+	case value.Duration:
+		return string(v)
+	}
+	return v
+}
+
+// templateError rewrites the errors from text/template to refer to the line and
+// column in the template text
+func templateError(err error) error {
+	msg := templateErrPrefix.ReplaceAllString(err.Error(), "")
+	if m := templateErrPrefix.FindStringSubmatch(err.Error()); m != nil {
+		return fmt.Errorf("invalid template at line %s: %s", m[1], msg)
+	}
+	return fmt.Errorf("invalid template: %w", err)
+}
+
+func Template(ctx context.Context, args []value.Value) (value.Value, bool, error) {
+	text, err := value.ToString(args[0])
+	if err != nil {
+		return nil, false, err
+	}
+
+	data, ok, err := value.NativeValue(args[1])
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+
+	tmpl, err := template.New(templateName).
+		Option("missingkey=error").
+		Funcs(templateFuncs).
+		Parse(text)
+	if err != nil {
+		return nil, false, value.NewErrPosition(getCallPos(ctx), templateError(err))
+	}
+
+	out := &strings.Builder{}
+	if err := tmpl.Execute(out, toTemplateData(data)); err != nil {
+		return nil, false, value.NewErrPosition(getCallPos(ctx), templateError(err))
+	}
+
+	return value.NewValue(out.String()), true, nil
+}
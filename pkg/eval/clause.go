@@ -0,0 +1,121 @@
+package eval
+
+import (
+	"context"
+
+	"github.com/acorn-io/aml/pkg/value"
+)
+
+// Clause is a for, if or let clause that follows the first for clause of a
+// comprehension, as in [for a in xs for b in a.items if b.enabled let n: b.name n]
+type Clause interface {
+	// each calls next with the names bound by each iteration of the clause. If the
+	// input of the clause is undefined next is called once with it as undef. each
+	// stops when next returns true, and returns true itself in that case.
+	each(ctx context.Context, next func(data map[string]any, undef value.Value) (bool, error)) (bool, error)
+}
+
+// ForClause iterates the items of Collection
+type ForClause struct {
+	Key        string
+	Value      string
+	Pattern    *Pattern
+	Collection Expression
+	Position   value.Position
+}
+
+func (f *ForClause) each(ctx context.Context, next func(map[string]any, value.Value) (bool, error)) (bool, error) {
+	collection, ok, err := f.Collection.ToValue(ctx)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	if undef := value.IsUndefined(collection); undef != nil {
+		return next(nil, undef)
+	}
+
+	list, err := toList(collection)
+	if err != nil {
+		return false, value.NewErrPosition(f.Position, err)
+	}
+
+	for _, item := range list {
+		data, err := bindItem(f.Key, f.Value, f.Pattern, item)
+		if err != nil {
+			return false, err
+		}
+		if stop, err := next(data, nil); err != nil || stop {
+			return stop, err
+		}
+	}
+
+	return false, nil
+}
+
+// IfClause skips the iterations for which Condition is false
+type IfClause struct {
+	Condition Expression
+	Position  value.Position
+}
+
+func (i *IfClause) each(ctx context.Context, next func(map[string]any, value.Value) (bool, error)) (bool, error) {
+	v, ok, err := i.Condition.ToValue(ctx)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	if undef := value.IsUndefined(v); undef != nil {
+		return next(nil, undef)
+	}
+
+	b, err := value.ToBool(v)
+	if err != nil {
+		return false, value.NewErrPosition(i.Position, err)
+	}
+	if !b {
+		return false, nil
+	}
+	return next(nil, nil)
+}
+
+// LetClause binds the value of Value to Name, or to the names of Pattern
+type LetClause struct {
+	Name    string
+	Pattern *Pattern
+	Value   Expression
+}
+
+func (l *LetClause) each(ctx context.Context, next func(map[string]any, value.Value) (bool, error)) (bool, error) {
+	v, ok, err := l.Value.ToValue(ctx)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	data := map[string]any{}
+	if l.Pattern != nil {
+		if err := l.Pattern.Bind(v, data); err != nil {
+			return false, err
+		}
+	} else {
+		data[l.Name] = v
+	}
+	return next(data, nil)
+}
+
+// iterate calls fn in a scope with data and the names bound by each iteration of
+// clauses. If the input of a clause is undefined fn is called with it as undef.
+func iterate(ctx context.Context, data map[string]any, clauses []Clause,
+	fn func(ctx context.Context, undef value.Value) (bool, error)) (bool, error) {
+	if len(data) > 0 {
+		_, ctx = GetScope(ctx).NewScope(ctx, ScopeData(data))
+	}
+	if len(clauses) == 0 {
+		return fn(ctx, nil)
+	}
+	return clauses[0].each(ctx, func(data map[string]any, undef value.Value) (bool, error) {
+		if undef != nil {
+			return fn(ctx, undef)
+		}
+		return iterate(ctx, data, clauses[1:], fn)
+	})
+}
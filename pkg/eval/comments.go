@@ -1,14 +1,80 @@
 package eval
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/acorn-io/aml/pkg/value"
+)
 
 type Comments struct {
 	Comments [][]string
 }
 
+// Last returns the description of the first comment group, without any doc tags
 func (c Comments) Last() string {
+	desc, _ := c.doc()
+	return desc
+}
+
+// Tags returns the doc tags of the first comment group
+func (c Comments) Tags() value.DocTags {
+	_, tags := c.doc()
+	return tags
+}
+
+// doc splits the first comment group into the description and the tags. A tag starts
+// a line with @deprecated, @example or @since and its text continues until the next
+// tag. Lines starting with any other @ word are part of the description.
+func (c Comments) doc() (string, value.DocTags) {
+	var (
+		tags        value.DocTags
+		description []string
+		tag         string
+		text        []string
+	)
 	if len(c.Comments) == 0 {
-		return ""
+		return "", tags
+	}
+
+	endTag := func() {
+		s := strings.TrimSpace(strings.Join(text, "\n"))
+		switch tag {
+		case "deprecated":
+			tags.Deprecated = true
+			tags.DeprecatedMessage = s
+		case "example":
+			if s != "" {
+				tags.Examples = append(tags.Examples, s)
+			}
+		case "since":
+			tags.Since = s
+		}
+		tag, text = "", nil
+	}
+
+	for _, line := range c.Comments[0] {
+		if name, rest, ok := docTag(line); ok {
+			endTag()
+			tag, text = name, []string{rest}
+		} else if tag != "" {
+			text = append(text, line)
+		} else {
+			description = append(description, line)
+		}
+	}
+	endTag()
+
+	return strings.TrimSpace(strings.Join(description, "\n")), tags
+}
+
+func docTag(line string) (string, string, bool) {
+	if !strings.HasPrefix(line, "@") {
+		return "", "", false
+	}
+	name, rest, _ := strings.Cut(line[1:], " ")
+	switch name {
+	case "deprecated", "example", "since":
+		return name, rest, true
 	}
-	return strings.TrimSpace(strings.Join(c.Comments[0], "\n"))
+	return "", "", false
 }
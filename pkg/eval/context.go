@@ -4,12 +4,16 @@ import (
 	"context"
 	"strconv"
 	"strings"
+
+	"github.com/acorn-io/aml/pkg/std"
+	"github.com/acorn-io/aml/pkg/value"
 )
 
 type (
 	schemaKey       struct{}
 	allowNewKeysKey struct{}
 	scopeKey        struct{}
+	callPosKey      struct{}
 )
 
 func WithScope(ctx context.Context, scope Scope) context.Context {
@@ -47,6 +51,26 @@ func IsAllowNewKeys(ctx context.Context) bool {
 	return *v
 }
 
+// withCallPos returns a context that records pos as the position of the function call
+// being evaluated, unless the call is in the std library, so that a native function
+// called through std can report errors where std was called
+func withCallPos(ctx context.Context, pos value.Position) context.Context {
+	if pos.Filename == std.File.Filename {
+		return ctx
+	}
+	return context.WithValue(ctx, callPosKey{}, pos)
+}
+
+// getCallPos returns the position of the function call outside of the std library being
+// evaluated, or value.NoPosition if there is none
+func getCallPos(ctx context.Context) value.Position {
+	pos, ok := ctx.Value(callPosKey{}).(value.Position)
+	if !ok {
+		return value.NoPosition
+	}
+	return pos
+}
+
 type Path []PathElement
 
 func (p Path) String() string {
@@ -122,3 +122,37 @@ func TestFileDescribe(t *testing.T) {
 		})
 	}
 }
+
+func TestEvalTolerant(t *testing.T) {
+	ctx := WithScope(context.Background(), Builtin)
+	dir := fmt.Sprintf("testdata/%s", t.Name())
+	files, err := os.ReadDir(dir)
+	require.Nil(t, err)
+
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".acorn") {
+			continue
+		}
+		t.Run(strings.TrimSuffix(file.Name(), ".acorn"), func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join(dir, file.Name()))
+			require.NoError(t, err)
+
+			ast, err := parser.ParseFile(file.Name(), bytes.NewReader(data), parser.Tolerant)
+			require.Error(t, err)
+
+			result, err := Build(ast)
+			require.NoError(t, err)
+
+			v, ok, err := result.ToValue(ctx)
+			require.NoError(t, err)
+			assert.True(t, ok)
+
+			nv, ok, err := value.NativeValue(v)
+			require.NoError(t, err)
+			require.True(t, ok)
+			data, err = json.MarshalIndent(nv, "", "  ")
+			require.NoError(t, err)
+			autogold.ExpectFile(t, autogold.Raw(data))
+		})
+	}
+}
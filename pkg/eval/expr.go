@@ -163,6 +163,16 @@ type Selector struct {
 	Pos      value.Position
 	Base     Expression
 	Key      Expression
+	Optional bool
+}
+
+// missing returns the value of a key that does not exist
+func missing(pos value.Position, key any) value.Value {
+	return value.Undefined{
+		Err:     newNotFound(pos, key, nil),
+		Pos:     pos,
+		Missing: true,
+	}
 }
 
 func (s *Selector) ToValue(ctx context.Context) (_ value.Value, _ bool, retErr error) {
@@ -183,6 +193,10 @@ func (s *Selector) ToValue(ctx context.Context) (_ value.Value, _ bool, retErr e
 		return nil, false, nil
 	}
 
+	if s.Optional && v.Kind() == value.NullKind {
+		return missing(s.Pos, key), true, nil
+	}
+
 	newValue, ok, err := value.Lookup(v, key)
 	if nf := (*errors.ErrValueNotDefined)(nil); errors.As(err, &nf) {
 		return value.Undefined{
@@ -193,10 +207,7 @@ func (s *Selector) ToValue(ctx context.Context) (_ value.Value, _ bool, retErr e
 		return nil, false, newNotFound(s.Pos, key, err)
 	}
 	if !ok {
-		return &value.Undefined{
-			Err: newNotFound(s.Pos, key, nil),
-			Pos: s.Pos,
-		}, true, nil
+		return missing(s.Pos, key), true, nil
 	}
 
 	return newValue, true, nil
@@ -207,6 +218,7 @@ type Index struct {
 	Pos      value.Position
 	Base     Expression
 	Index    Expression
+	Optional bool
 }
 
 func (i *Index) ToValue(ctx context.Context) (value.Value, bool, error) {
@@ -220,10 +232,20 @@ func (i *Index) ToValue(ctx context.Context) (value.Value, bool, error) {
 		return nil, ok, err
 	}
 
+	if i.Optional {
+		if out, err := i.outOfRange(base, indexValue); err != nil {
+			return nil, false, value.NewErrPosition(i.Pos, err)
+		} else if out {
+			return missing(i.Pos, indexValue), true, nil
+		}
+	}
+
 	if indexValue.Kind() == value.StringKind {
 		v, ok, err := value.Lookup(base, indexValue)
 		if err != nil {
 			return nil, false, err
+		} else if !ok && i.Optional {
+			return missing(i.Pos, indexValue), true, nil
 		} else if !ok {
 			return nil, false, newNotFound(i.Pos, indexValue, nil)
 		}
@@ -237,6 +259,50 @@ func (i *Index) ToValue(ctx context.Context) (value.Value, bool, error) {
 	return result, ok, nil
 }
 
+// outOfRange returns true if base is null or an array that does not have the index
+func (i *Index) outOfRange(base, index value.Value) (bool, error) {
+	if base.Kind() == value.NullKind {
+		return true, nil
+	}
+	if base.Kind() != value.ArrayKind || index.Kind() != value.NumberKind {
+		return false, nil
+	}
+
+	l, err := value.Len(base)
+	if err != nil {
+		return false, err
+	}
+	length, err := value.ToInt(l)
+	if err != nil {
+		return false, err
+	}
+	idx, err := value.ToInt(index)
+	if err != nil {
+		return false, err
+	}
+	return idx < 0 || idx >= length, nil
+}
+
+// Coalesce is the ?? operator. It yields the left value unless it is null or
+// missing, in which case the right value is evaluated.
+type Coalesce struct {
+	Comments Comments
+	Pos      value.Position
+	Left     Expression
+	Right    Expression
+}
+
+func (c *Coalesce) ToValue(ctx context.Context) (value.Value, bool, error) {
+	left, ok, err := c.Left.ToValue(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if ok && !value.IsMissing(left) && left.Kind() != value.NullKind {
+		return left, true, nil
+	}
+	return c.Right.ToValue(ctx)
+}
+
 type Slice struct {
 	Comments Comments
 	Pos      value.Position
@@ -296,8 +362,10 @@ func (c *Call) ToValue(ctx context.Context) (value.Value, bool, error) {
 		return nil, ok, err
 	}
 
-	// Disable schema evaluation
-	ctx = WithSchema(ctx, false)
+	// Disable schema evaluation, unless the function takes schema arguments
+	if sa, ok := v.(SchemaArguments); !ok || !sa.SchemaArguments() {
+		ctx = WithSchema(ctx, false)
+	}
 
 	var args []value.CallArgument
 	for _, field := range c.Args {
@@ -315,7 +383,7 @@ func (c *Call) ToValue(ctx context.Context) (value.Value, bool, error) {
 		args = append(args, arg)
 	}
 
-	v, ok, err = value.Call(ctx, v, args...)
+	v, ok, err = value.Call(withCallPos(ctx, c.Pos), v, args...)
 	if err != nil {
 		return v, ok, value.NewErrPosition(c.Pos, err)
 	}
@@ -400,7 +468,9 @@ type For struct {
 	Comments   Comments
 	Key        string
 	Value      string
+	Pattern    *Pattern
 	Collection Expression
+	Clauses    []Clause
 	Body       Expression
 	Else       Expression
 	Merge      bool
@@ -484,7 +554,8 @@ func (f *For) ToValue(ctx context.Context) (value.Value, bool, error) {
 		}
 	}
 
-	for i, item := range list {
+	var index int
+	for _, item := range list {
 		select {
 		case <-ctx.Done():
 			return nil, false, value.NewErrPosition(f.Position,
@@ -492,52 +563,58 @@ func (f *For) ToValue(ctx context.Context) (value.Value, bool, error) {
 		default:
 		}
 
-		data := map[string]any{}
-		if f.Key != "" {
-			data[f.Key] = item.Key
-		}
-		if f.Value != "" {
-			data[f.Value] = item.Value
-		}
-		if prev == nil {
-			data["prev"] = value.NewObject(nil)
-		} else {
-			data["prev"] = prev
-		}
-
-		ctx := value.WithIndexPath(ctx, i)
-		_, ctx = GetScope(ctx).NewScope(ctx, ScopeData(data))
-
-		newValue, ok, err := f.Body.ToValue(ctx)
+		data, err := bindItem(f.Key, f.Value, f.Pattern, item)
 		if err != nil {
 			return nil, false, err
 		}
-		if !ok {
-			continue
-		}
 
-		var (
-			shouldSkip  bool
-			shouldBreak bool
-		)
+		stop, err := iterate(ctx, data, f.Clauses, func(ctx context.Context, undef value.Value) (bool, error) {
+			newValue := undef
+			if newValue == nil {
+				data := map[string]any{}
+				if prev == nil {
+					data["prev"] = value.NewObject(nil)
+				} else {
+					data["prev"] = prev
+				}
+
+				ctx := value.WithIndexPath(ctx, index)
+				_, ctx = GetScope(ctx).NewScope(ctx, ScopeData(data))
+				index++
+
+				v, ok, err := f.Body.ToValue(ctx)
+				if err != nil || !ok {
+					return false, err
+				}
+				newValue = v
+			}
 
-		if lc, ok := newValue.(*LoopControl); ok {
-			shouldSkip = lc.Skip
-			if lc.Break {
-				newValue = lc.Value
-				shouldBreak = true
+			var (
+				shouldSkip  bool
+				shouldBreak bool
+			)
+
+			if lc, ok := newValue.(*LoopControl); ok {
+				shouldSkip = lc.Skip
+				if lc.Break {
+					newValue = lc.Value
+					shouldBreak = true
+				}
 			}
-		}
 
-		if !shouldSkip {
-			prev, err = appendValue(prev, newValue)
-			if err != nil {
-				return nil, false, err
+			if !shouldSkip {
+				prev, err = appendValue(prev, newValue)
+				if err != nil {
+					return false, err
+				}
+				array = append(array, newValue)
 			}
-			array = append(array, newValue)
-		}
 
-		if shouldBreak {
+			return shouldBreak, nil
+		})
+		if err != nil {
+			return nil, false, err
+		} else if stop {
 			break
 		}
 	}
@@ -554,6 +631,23 @@ func (f *For) ToValue(ctx context.Context) (value.Value, bool, error) {
 	return array, true, nil
 }
 
+// bindItem returns the names bound by a for clause to an item of its collection
+func bindItem(key, name string, pattern *Pattern, item entry) (map[string]any, error) {
+	data := map[string]any{}
+	if key != "" {
+		data[key] = item.Key
+	}
+	if name != "" {
+		data[name] = item.Value
+	}
+	if pattern != nil {
+		if err := pattern.Bind(item.Value, data); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
 func appendValue(left, right value.Value) (value.Value, error) {
 	if undef := value.IsUndefined(left, right); undef != nil {
 		return undef, nil
@@ -0,0 +1,60 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/acorn-io/aml/pkg/value"
+)
+
+// Extends is the value of a define that extends other schemas, as in
+// define Web extends Base: {...}. The fields of the bases are inherited and can be
+// narrowed by the fields of Value.
+type Extends struct {
+	Pos   value.Position
+	Bases []Expression
+	Value Expression
+}
+
+func (e *Extends) ToValue(ctx context.Context) (value.Value, bool, error) {
+	var base value.Schema
+	for _, expr := range e.Bases {
+		v, ok, err := expr.ToValue(ctx)
+		if err != nil || !ok {
+			return nil, ok, err
+		} else if undef := value.IsUndefined(v); undef != nil {
+			return undef, true, nil
+		}
+
+		schema, ok := v.(value.Schema)
+		if !ok || schema.TargetKind() != value.ObjectKind {
+			return nil, false, value.NewErrPosition(e.Pos,
+				fmt.Errorf("can only extend object schemas, got %s", value.TargetKind(v)))
+		}
+
+		if base == nil {
+			base = schema
+		} else if base, err = base.MergeType(schema); err != nil {
+			return nil, false, value.NewErrPosition(e.Pos, err)
+		}
+	}
+
+	v, ok, err := e.Value.ToValue(ctx)
+	if err != nil || !ok {
+		return nil, ok, err
+	} else if undef := value.IsUndefined(v); undef != nil {
+		return undef, true, nil
+	}
+
+	derived, ok := v.(value.Schema)
+	if !ok || derived.TargetKind() != value.ObjectKind {
+		return nil, false, value.NewErrPosition(e.Pos,
+			fmt.Errorf("the value of a define that extends schemas must be an object schema, got %s", value.TargetKind(v)))
+	}
+
+	result, err := value.Extend(ctx, base, derived)
+	if err != nil {
+		return nil, false, value.NewErrPosition(e.Pos, err)
+	}
+	return result, true, nil
+}
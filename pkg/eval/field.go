@@ -22,12 +22,14 @@ type Field interface {
 }
 
 type KeyValue struct {
-	Comments Comments
-	Key      FieldKey
-	Value    Expression
-	Pos      value.Position
-	Local    bool
-	Optional bool
+	Comments   Comments
+	Attributes value.Attributes
+	Key        FieldKey
+	Value      Expression
+	Pos        value.Position
+	Local      bool
+	Optional   bool
+	Override   bool
 }
 
 func (k *KeyValue) IsForLookup(_ context.Context) bool {
@@ -49,7 +51,7 @@ func (k *KeyValue) Position() value.Position {
 }
 
 func (k *KeyValue) returnTuple(ctx context.Context, key string) (value.Value, bool, error) {
-	v, ok, err := k.getValueValue(ctx)
+	v, ok, err := k.getValueValue(ctx, key)
 	if err != nil || !ok {
 		return nil, ok, err
 	}
@@ -78,6 +80,8 @@ func (k *KeyValue) returnTuple(ctx context.Context, key string) (value.Value, bo
 						Match:       k.Key.Match != nil,
 						Optional:    k.Optional,
 						Description: k.Comments.Last(),
+						DocTags:     k.Comments.Tags(),
+						Attributes:  k.Attributes,
 						Schema:      resultTS,
 					},
 				},
@@ -93,14 +97,46 @@ func (k *KeyValue) returnTuple(ctx context.Context, key string) (value.Value, bo
 	}, true, nil
 }
 
-func (k *KeyValue) getValueValue(ctx context.Context) (ret value.Value, _ bool, _ error) {
+// isRecursive returns true if the value of the field is a schema that can refer to
+// itself by the key of the field
+func (k *KeyValue) isRecursive(ctx context.Context) bool {
+	if k.Local || k.Key.Match != nil {
+		return false
+	}
+	switch k.Value.(type) {
+	case *Schema:
+		return true
+	case *Struct:
+		return IsSchema(ctx)
+	}
+	return false
+}
+
+func (k *KeyValue) getValueValue(ctx context.Context, key string) (ret value.Value, _ bool, _ error) {
+	var ref *value.TypeSchema
+	if k.isRecursive(ctx) {
+		// The key refers to the schema being defined until it is evaluated
+		ref = value.NewReference(k.Pos, value.GetPath(ctx))
+		_, ctx = GetScope(ctx).NewScope(ctx, ScopeData{
+			key: ref,
+		})
+	}
+
 	v, ok, err := k.Value.ToValue(ctx)
 	if err != nil || !ok {
 		return nil, ok, err
 	}
+	if ref != nil {
+		if err := ref.Resolve(v); err != nil {
+			return nil, false, err
+		}
+	}
 	if value.IsSimpleKind(v.Kind()) && IsSchema(ctx) {
 		return value.NewMatchTypeWithDefault(k.Pos, v), true, nil
 	}
+	if k.Override && !IsSchema(ctx) {
+		return newOverridden(v), true, nil
+	}
 	return v, true, nil
 }
 
@@ -0,0 +1,61 @@
+package eval
+
+import (
+	"github.com/acorn-io/aml/pkg/value"
+)
+
+// overridden is the value of a field written as key!: value. It replaces the values
+// of the other fields with the same key instead of being merged with them, whether
+// they come before or after it. Of two overrides the last one wins. Schemas still
+// apply to the value.
+type overridden struct {
+	value.Deferred
+
+	value value.Value
+}
+
+func newOverridden(v value.Value) overridden {
+	if o, ok := v.(overridden); ok {
+		return o
+	}
+	return overridden{
+		Deferred: value.Deferred{
+			Resolve: func() (value.Value, error) {
+				return v, nil
+			},
+			KindResolver: func() value.Kind {
+				return v.Kind()
+			},
+		},
+		value: v,
+	}
+}
+
+func (o overridden) RightMergePriority() value.RightMergePriority {
+	return value.OverridePriority
+}
+
+func (o overridden) RightMerge(left value.Value) (value.Value, error) {
+	if left.Kind() == value.SchemaKind {
+		return o.validate(left)
+	}
+	return o, nil
+}
+
+func (o overridden) Merge(right value.Value) (value.Value, error) {
+	if right, ok := right.(overridden); ok {
+		return right, nil
+	}
+	if right.Kind() == value.SchemaKind {
+		return o.validate(right)
+	}
+	return o, nil
+}
+
+func (o overridden) validate(schema value.Value) (value.Value, error) {
+	v, err := value.Merge(schema, o.value)
+	if err != nil {
+		return nil, err
+	}
+	return newOverridden(v), nil
+}
@@ -0,0 +1,115 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/acorn-io/aml/pkg/value"
+)
+
+// Pattern destructures a value into names, as in for {name, port} in services or
+// let [host, port]: std.split(addr, ":"). A pattern is either a Name, an object
+// pattern of Fields, or a list pattern of Items.
+type Pattern struct {
+	Pos    value.Position
+	Name   string
+	Fields []PatternField
+	Items  []*Pattern
+	List   bool
+}
+
+// PatternField destructures the value of Key in an object
+type PatternField struct {
+	Key     string
+	Pattern *Pattern
+}
+
+// Names returns the names that are bound by the pattern in the order they are written
+func (p *Pattern) Names() (result []string) {
+	switch {
+	case p.List:
+		for _, item := range p.Items {
+			result = append(result, item.Names()...)
+		}
+	case p.Name == "":
+		for _, field := range p.Fields {
+			result = append(result, field.Pattern.Names()...)
+		}
+	default:
+		result = append(result, p.Name)
+	}
+	return result
+}
+
+// Bind destructures v and adds the value of each name of the pattern to data. An
+// undefined value binds all names to undefined.
+func (p *Pattern) Bind(v value.Value, data map[string]any) error {
+	if undef := value.IsUndefined(v); undef != nil {
+		for _, name := range p.Names() {
+			data[name] = undef
+		}
+		return nil
+	}
+
+	switch {
+	case p.List:
+		if v.Kind() != value.ArrayKind {
+			return value.NewErrPosition(p.Pos,
+				fmt.Errorf("can not destructure kind %s with a list pattern, expected an array", v.Kind()))
+		}
+		items, err := value.ToValueArray(v)
+		if err != nil {
+			return value.NewErrPosition(p.Pos, err)
+		}
+		if len(items) != len(p.Items) {
+			return value.NewErrPosition(p.Pos,
+				fmt.Errorf("list pattern of length %d does not match array of length %d", len(p.Items), len(items)))
+		}
+		for i, item := range p.Items {
+			if err := item.Bind(items[i], data); err != nil {
+				return err
+			}
+		}
+	case p.Name == "":
+		if !value.IsObjectLike(v) {
+			return value.NewErrPosition(p.Pos,
+				fmt.Errorf("can not destructure kind %s with a struct pattern, expected an object", v.Kind()))
+		}
+		for _, field := range p.Fields {
+			fieldValue, ok, err := value.Lookup(v, value.NewValue(field.Key))
+			if err != nil {
+				return value.NewErrPosition(field.Pattern.Pos, err)
+			} else if !ok {
+				return value.NewErrPosition(field.Pattern.Pos,
+					fmt.Errorf("key %s not found in object destructured by pattern", field.Key))
+			}
+			if err := field.Pattern.Bind(fieldValue, data); err != nil {
+				return err
+			}
+		}
+	default:
+		data[p.Name] = v
+	}
+
+	return nil
+}
+
+// Destructure is the value of one name of a let binding that destructures Value
+type Destructure struct {
+	Pattern *Pattern
+	Name    string
+	Value   Expression
+}
+
+func (d *Destructure) ToValue(ctx context.Context) (value.Value, bool, error) {
+	v, ok, err := d.Value.ToValue(ctx)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+
+	data := map[string]any{}
+	if err := d.Pattern.Bind(v, data); err != nil {
+		return nil, false, err
+	}
+	return data[d.Name].(value.Value), true, nil
+}
@@ -0,0 +1,101 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/acorn-io/aml/pkg/value"
+)
+
+// type assertions
+var (
+	_ Field      = (*Spread)(nil)
+	_ Expression = (*Spread)(nil)
+)
+
+// Spread is ...expr in a struct or a list. In a struct the fields of the object replace
+// the fields with the same key before it, and are replaced by the fields with the same
+// key after it. In a list the elements of the array are added to the list.
+type Spread struct {
+	Pos        value.Position
+	Comments   Comments
+	Expression Expression
+}
+
+func (s *Spread) IsForLookup(_ context.Context) bool {
+	return true
+}
+
+func (s *Spread) IsForValue(_ context.Context) bool {
+	return true
+}
+
+func (s *Spread) Position() value.Position {
+	return s.Pos
+}
+
+func (s *Spread) ToValue(ctx context.Context) (value.Value, bool, error) {
+	return s.Expression.ToValue(ctx)
+}
+
+func (s *Spread) ToValueForIndex(ctx context.Context, _ int) (value.Value, bool, error) {
+	v, ok, err := s.Expression.ToValue(ctx)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+	if value.IsUndefined(v) != nil || value.IsObjectLike(v) {
+		return v, true, nil
+	}
+	return nil, false, value.NewErrPosition(s.Pos,
+		fmt.Errorf("can not spread kind %s in a struct, expected an object", v.Kind()))
+}
+
+// items returns the elements of the array v that is spread in a list
+func (s *Spread) items(v value.Value) ([]value.Value, error) {
+	if ts, ok := v.(*value.TypeSchema); ok && ts.Array != nil {
+		var result []value.Value
+		for _, item := range ts.ValidArrayItems() {
+			result = append(result, item)
+		}
+		return result, nil
+	}
+	if v.Kind() != value.ArrayKind {
+		return nil, value.NewErrPosition(s.Pos,
+			fmt.Errorf("can not spread kind %s in a list, expected an array", v.Kind()))
+	}
+	return value.ToValueArray(v)
+}
+
+// spreadMerger returns the function that adds the value v of field to the value of a
+// struct. A spread, and a field that sets a key of a previous spread, replace the
+// fields with the same keys instead of being merged with them. spreadKeys tracks the
+// keys set by the spreads of the struct so far.
+func spreadMerger(field Field, v value.Value, spreadKeys map[string]struct{}) (func(left, right value.Value) (value.Value, error), error) {
+	keys, err := value.KeysIfSupported(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := field.(*Spread); ok {
+		for _, key := range keys {
+			spreadKeys[key] = struct{}{}
+		}
+		return value.Override, nil
+	}
+
+	override := false
+	for _, key := range keys {
+		if _, ok := spreadKeys[key]; ok {
+			delete(spreadKeys, key)
+			override = true
+		}
+	}
+	if override {
+		return value.Override, nil
+	}
+	return mergeValues, nil
+}
+
+func mergeValues(left, right value.Value) (value.Value, error) {
+	return value.Merge(left, right)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	amlerrors "github.com/acorn-io/aml/pkg/errors"
 	"github.com/acorn-io/aml/pkg/value"
 )
 
@@ -51,6 +52,8 @@ func (s *Struct) evaluateFields(ctx context.Context, storage *structScopeStorage
 		scopeValue  value.Value
 		returnValue value.Value
 		loopControl *LoopControl
+		spreadKeys  = map[string]struct{}{}
+		positions   = map[string]Field{}
 	)
 
 	for i, field := range s.Fields {
@@ -78,17 +81,22 @@ func (s *Struct) evaluateFields(ctx context.Context, storage *structScopeStorage
 			continue
 		}
 
+		merge, err := spreadMerger(field, v, spreadKeys)
+		if err != nil {
+			return nil, false, value.NewErrPosition(field.Position(), err)
+		}
+
 		if field.IsForLookup(ctx) {
-			scopeValue, err = value.Merge(scopeValue, v)
+			scopeValue, err = mergeField(merge, scopeValue, v, field, positions)
 			if err != nil {
-				return nil, false, value.NewErrPosition(field.Position(), err)
+				return nil, false, err
 			}
 		}
 
 		if field.IsForValue(ctx) {
-			returnValue, err = value.Merge(returnValue, v)
+			returnValue, err = mergeField(merge, returnValue, v, field, positions)
 			if err != nil {
-				return nil, false, value.NewErrPosition(field.Position(), err)
+				return nil, false, err
 			}
 		}
 	}
@@ -112,3 +120,115 @@ func (s *Struct) evaluateFields(ctx context.Context, storage *structScopeStorage
 
 	return loopControl.withValue(returnValue), mustRetry, nil
 }
+
+// mergeField merges the value v of field into left. If the merge fails the error
+// includes the position of the previous field that set the conflicting key. positions
+// tracks the first field that set each key.
+func mergeField(merge func(left, right value.Value) (value.Value, error), left, v value.Value, field Field, positions map[string]Field) (value.Value, error) {
+	result, err := merge(left, v)
+	if err != nil {
+		return nil, conflict(err, left, v, field, positions)
+	}
+
+	keys, err := value.KeysIfSupported(v)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if _, ok := positions[key]; !ok {
+			positions[key] = field
+		}
+	}
+	return result, nil
+}
+
+// conflict adds the key and the position of the previous value that could not be
+// merged with the value v of field to err
+func conflict(err error, left, v value.Value, field Field, positions map[string]Field) error {
+	if left == nil || !value.IsObjectLike(left) || !value.IsObjectLike(v) {
+		return value.NewErrPosition(field.Position(), err)
+	}
+
+	keys, keysErr := value.Keys(v)
+	if keysErr != nil {
+		return value.NewErrPosition(field.Position(), err)
+	}
+
+	for _, key := range keys {
+		prev, ok := positions[key]
+		if !ok {
+			continue
+		}
+		path, prev, next, ok := conflictingFields(key, prev, field, left, v)
+		if !ok {
+			continue
+		}
+		return value.NewErrPosition(next.Position(), &amlerrors.ErrConflict{
+			Path:     path,
+			Key:      path[len(path)-1],
+			Previous: prev.Position(),
+			Err:      err,
+		})
+	}
+
+	return value.NewErrPosition(field.Position(), err)
+}
+
+// conflictingFields returns true if the values of key in left and right can not be
+// merged. Where both fields set the key with a struct literal it follows the
+// conflicting keys of the literals, and returns the path to and fields of the
+// innermost conflict, such as resources.cpu rather than resources.
+func conflictingFields(key string, prev, next Field, left, right value.Value) (path []string, _, _ Field, _ bool) {
+	leftValue, ok, err := value.Lookup(left, value.NewValue(key))
+	if err != nil || !ok {
+		return nil, nil, nil, false
+	}
+	rightValue, ok, err := value.Lookup(right, value.NewValue(key))
+	if err != nil || !ok {
+		return nil, nil, nil, false
+	}
+	if _, err := value.Merge(leftValue, rightValue); err == nil {
+		return nil, nil, nil, false
+	}
+
+	path = []string{key}
+	prevStruct, nextStruct := structLiteral(prev), structLiteral(next)
+	if prevStruct == nil || nextStruct == nil || !value.IsObjectLike(leftValue) || !value.IsObjectLike(rightValue) {
+		return path, prev, next, true
+	}
+	keys, err := value.Keys(rightValue)
+	if err != nil {
+		return path, prev, next, true
+	}
+	for _, subKey := range keys {
+		prevField, nextField := prevStruct.field(subKey), nextStruct.field(subKey)
+		if prevField == nil || nextField == nil {
+			continue
+		}
+		if subPath, innerPrev, innerNext, ok := conflictingFields(subKey, prevField, nextField, leftValue, rightValue); ok {
+			return append(path, subPath...), innerPrev, innerNext, true
+		}
+	}
+	return path, prev, next, true
+}
+
+// structLiteral returns the struct that is the value of field, as in key: {...}, or nil
+// if the value is not a struct literal
+func structLiteral(field Field) *Struct {
+	kv, ok := field.(*KeyValue)
+	if !ok {
+		return nil
+	}
+	s, _ := kv.Value.(*Struct)
+	return s
+}
+
+// field returns the field of s that sets key, or nil if the key of no field is key
+func (s *Struct) field(key string) Field {
+	for _, field := range s.Fields {
+		if kv, ok := field.(*KeyValue); ok && kv.Key.Match == nil && kv.Key.Interpolation == nil && kv.Key.Key == key {
+			return kv
+		}
+	}
+	return nil
+}
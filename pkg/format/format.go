@@ -196,7 +196,8 @@ func (f *formatter) wsOverride(def whiteSpace) whiteSpace {
 func (f *formatter) onOneLine(node ast.Node) bool {
 	a := node.Pos()
 	b := node.End()
-	if a.IsValid() && b.IsValid() {
+	// the end of a node in a partial AST from parser.Tolerant may be past the end of the file
+	if a.IsInFile() && b.IsInFile() {
 		return f.lineFor(a) == f.lineFor(b)
 	}
 	// TODO: walk and look at relative positions to determine the same?
@@ -255,13 +256,14 @@ func (f *formatter) printComment(cg *ast.CommentGroup) {
 		if !printBlank {
 			if isEnd {
 				f.Print(vtab)
-			} else {
+			} else if len(f.output) > 0 {
 				f.Print(blank)
 			}
 		}
 		f.Print(c.Slash)
 		f.Print(c)
-		if isEnd {
+		// doc comments are on their own lines, even /*-style comments
+		if isEnd || cg.Doc {
 			f.Print(newline)
 			if cg.Doc {
 				f.Print(nooverride)
@@ -41,3 +41,29 @@ func TestFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatTolerant(t *testing.T) {
+	dir := fmt.Sprintf("testdata/%s", t.Name())
+	files, err := os.ReadDir(dir)
+	require.Nil(t, err)
+
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".acorn") {
+			continue
+		}
+		t.Run(strings.TrimSuffix(file.Name(), ".acorn"), func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join(dir, file.Name()))
+			require.NoError(t, err)
+
+			ast, err := parser.ParseFile(file.Name(), bytes.NewReader(data), parser.Tolerant)
+			require.Error(t, err)
+
+			out, err := Node(ast)
+			if err != nil {
+				autogold.ExpectFile(t, err)
+			} else {
+				autogold.ExpectFile(t, autogold.Raw(out))
+			}
+		})
+	}
+}
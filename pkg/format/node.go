@@ -73,7 +73,7 @@ func hasDocComments(d ast.Decl) bool {
 	case *ast.Field:
 		return len(ast.Comments(x.Label)) > 0
 	case *ast.LetClause:
-		return len(x.Ident.Comments()) > 0
+		return x.Ident != nil && len(x.Ident.Comments()) > 0
 	}
 	return false
 }
@@ -90,6 +90,18 @@ func isDef(s string) bool {
 	return strings.HasPrefix(s, "#") || strings.HasPrefix(s, "_#")
 }
 
+// badNode prints the source text of a bad node, which is an error if it is not known
+// and the node is not empty
+func (f *formatter) badNode(n ast.Node, text string) {
+	switch {
+	case text != "":
+		f.print(n.Pos(), text)
+	case n.Pos().IsInFile() && n.End().IsInFile() && n.Pos().Offset() == n.End().Offset():
+	default:
+		f.errf(n, "invalid syntax")
+	}
+}
+
 func (f *formatter) walkDeclList(list []ast.Decl) {
 	f.before(nil)
 	d := 0
@@ -110,6 +122,9 @@ func (f *formatter) walkDeclList(list []ast.Decl) {
 						f.print(newsection)
 					}
 
+				case *ast.Spread:
+					// a spread is grouped with the fields around it
+
 				default:
 					f.print(newsection)
 				}
@@ -225,6 +240,15 @@ func (f *formatter) decl(decl ast.Decl) {
 		} else {
 			f.label(n.Label, n.Constraint, true)
 		}
+		if n.Extends.IsValid() {
+			f.print(blank, nooverride, n.Extends, "extends", blank)
+			for i, base := range n.Bases {
+				if i > 0 {
+					f.print(token.COMMA, blank)
+				}
+				f.expr(base)
+			}
+		}
 		f.print(noblank, nooverride, n.Colon, token.COLON)
 
 		if mem := f.inlineField(n); mem != nil {
@@ -263,19 +287,26 @@ func (f *formatter) decl(decl ast.Decl) {
 			f.visitComments(f.current.pos)
 		}
 
+		for _, a := range n.Attrs {
+			f.print(blank, nooverride)
+			f.print(a.At, a)
+		}
+
 		if nextFF {
 			f.print(formfeed)
 		}
 
 	case *ast.BadDecl:
-		f.print(n.From, "*bad decl*", declcomma)
+		// partial ASTs from parser.Tolerant keep the source of invalid declarations
+		f.badNode(n, n.Text)
+		f.print(declcomma)
 
 	case *ast.LetClause:
 		if !decl.Pos().HasRelPos() || decl.Pos().RelPos() >= token.Newline {
 			f.print(formfeed)
 		}
 		f.print(n.Let, token.LET, blank, nooverride)
-		f.expr(n.Ident)
+		f.letName(n)
 		f.print(noblank, nooverride, n.Colon, token.COLON, blank)
 		f.expr(n.Expr)
 		f.print(declcomma) // implied
@@ -366,7 +397,8 @@ func (f *formatter) exprRaw(expr ast.Expr, prec1, depth int) {
 
 	switch x := expr.(type) {
 	case *ast.BadExpr:
-		f.print(x.From, "INVALID EXPRESSION")
+		// partial ASTs from parser.Tolerant keep the source of invalid expressions
+		f.badNode(x, x.Text)
 
 	case *ast.Ident:
 		f.print(x.NamePos, x)
@@ -421,7 +453,11 @@ func (f *formatter) exprRaw(expr ast.Expr, prec1, depth int) {
 
 	case *ast.IndexExpr:
 		f.expr1(x.X, token.HighestPrec, 1)
-		f.print(x.Lbrack, token.LBRACK)
+		if x.Optional {
+			f.print(x.Lbrack, token.OPTLBRACK)
+		} else {
+			f.print(x.Lbrack, token.LBRACK)
+		}
 		f.expr0(x.Index, depth+1)
 		f.print(x.Rbrack, token.RBRACK)
 
@@ -527,6 +563,7 @@ func (f *formatter) exprRaw(expr ast.Expr, prec1, depth int) {
 		f.print(x.For, "for", blank)
 		f.print(indent)
 		f.clause(x.Clause)
+		f.clauses(x.Clauses)
 		f.expr(x.Struct)
 		if x.Else != nil {
 			f.expr(x.Else)
@@ -541,6 +578,18 @@ func (f *formatter) exprRaw(expr ast.Expr, prec1, depth int) {
 			f.expr(x.Else)
 		}
 
+	case *ast.Spread:
+		f.print(x.Ellipsis, token.ELLIPSIS, nooverride)
+		f.expr(x.X)
+
+	case *ast.ConditionalExpr:
+		f.print(x.If, "if", blank)
+		f.expr(x.Condition)
+		f.print(blank, x.Then, "then", blank)
+		f.expr(x.X)
+		f.print(blank, x.Else, "else", blank)
+		f.expr(x.Y)
+
 	case *ast.Else:
 		f.print(x.Else, "else", blank)
 		if x.If != nil {
@@ -554,6 +603,7 @@ func (f *formatter) exprRaw(expr ast.Expr, prec1, depth int) {
 		f.print(x.Lbrack, token.LBRACK, noblank, nooverride)
 		f.print(x.For, "for", blank)
 		f.clause(x.Clause)
+		f.clauses(x.Clauses)
 		f.print(blank, nooverride)
 		f.expr(x.Value)
 		f.print(noblank, nooverride)
@@ -575,7 +625,11 @@ func (f *formatter) clause(clause ast.Clause) {
 			f.current.pos++
 			f.visitComments(f.current.pos)
 		}
-		f.label(n.Value, token.ILLEGAL, true)
+		if n.Pattern != nil {
+			f.pattern(n.Pattern)
+		} else {
+			f.label(n.Value, token.ILLEGAL, true)
+		}
 		f.print(blank, n.In, "in", blank)
 		f.expr(n.Source)
 		f.markUnindentLine()
@@ -588,7 +642,7 @@ func (f *formatter) clause(clause ast.Clause) {
 	case *ast.LetClause:
 		f.print(n.Let, token.LET, blank, nooverride)
 		f.print(indent)
-		f.expr(n.Ident)
+		f.letName(n)
 		f.print(noblank, nooverride, n.Colon, token.COLON, blank)
 		f.expr(n.Expr)
 		f.markUnindentLine()
@@ -598,6 +652,61 @@ func (f *formatter) clause(clause ast.Clause) {
 	}
 }
 
+// clauses prints the clauses that follow the first for clause of a comprehension
+func (f *formatter) clauses(list []ast.Clause) {
+	for _, clause := range list {
+		f.print(blank, nooverride)
+		switch n := clause.(type) {
+		case *ast.ForClause:
+			f.print(n.For, "for", blank)
+		case *ast.IfClause:
+			f.print(n.If, "if", blank)
+		}
+		f.clause(clause)
+	}
+}
+
+func (f *formatter) letName(n *ast.LetClause) {
+	if n.Pattern != nil {
+		f.pattern(n.Pattern)
+	} else {
+		f.expr(n.Ident)
+	}
+}
+
+// pattern prints a destructuring pattern on a single line
+func (f *formatter) pattern(x ast.Expr) {
+	switch n := x.(type) {
+	case *ast.StructLit:
+		f.print(n.Lbrace, token.LBRACE, noblank)
+		for i, elt := range n.Elts {
+			if i > 0 {
+				f.print(noblank, token.COMMA, blank)
+			}
+			switch elt := elt.(type) {
+			case *ast.Field:
+				f.label(elt.Label, token.ILLEGAL, true)
+				f.print(noblank, nooverride, elt.Colon, token.COLON, blank)
+				f.pattern(elt.Value)
+			case *ast.EmbedDecl:
+				f.pattern(elt.Expr)
+			}
+		}
+		f.print(noblank, n.Rbrace, token.RBRACE)
+	case *ast.ListLit:
+		f.print(n.Lbrack, token.LBRACK, noblank)
+		for i, elt := range n.Elts {
+			if i > 0 {
+				f.print(noblank, token.COMMA, blank)
+			}
+			f.pattern(elt)
+		}
+		f.print(noblank, n.Rbrack, token.RBRACK)
+	default:
+		f.expr(x)
+	}
+}
+
 func walkBinary(e *ast.BinaryExpr) (has6, has7, has8 bool, maxProblem int) {
 	switch e.Op.Precedence() {
 	case 6:
@@ -626,7 +735,8 @@ func walkBinary(e *ast.BinaryExpr) (has6, has7, has8 bool, maxProblem int) {
 
 	switch r := e.Y.(type) {
 	case *ast.BinaryExpr:
-		if r.Op.Precedence() <= e.Op.Precedence() {
+		if r.Op.Precedence() < e.Op.Precedence() ||
+			r.Op.Precedence() == e.Op.Precedence() && !e.Op.RightAssociative() {
 			// parens will be inserted.
 			// pretend this is an *syntax.ParenExpr and do nothing.
 			break
@@ -700,16 +810,17 @@ func reduceDepth(depth int) int {
 //
 // The precedences are:
 //
-//	7             *  /  % quo rem div mod
+//	8             **
+//	7             *  /  %  div
 //	6             +  -
 //	5             ==  !=  <  <=  >  >=
 //	4             &&
 //	3             ||
-//	2             &
-//	1             |
+//	2             ??
 //
 // The only decision is whether there will be spaces around levels 6 and 7.
-// There are never spaces at level 8 (unary), and always spaces at levels 5 and below.
+// There are never spaces at levels 8 (**) and 9 (unary), and always spaces at levels 5
+// and below.
 //
 // To choose the cutoff, look at the whole expression but excluding primary
 // expressions (function calls, parenthesized exprs), and apply these rules:
@@ -748,7 +859,12 @@ func (f *formatter) binaryExpr(x *ast.BinaryExpr, prec1, cutoff, depth int) {
 
 	printBlank := prec < cutoff
 
-	f.expr1(x.X, prec, depth+diffPrec(x.X, prec))
+	xPrec, yPrec := prec, prec+1
+	if x.Op.RightAssociative() {
+		xPrec, yPrec = prec+1, prec
+	}
+
+	f.expr1(x.X, xPrec, depth+diffPrec(x.X, prec))
 	f.print(nooverride)
 	if printBlank {
 		f.print(blank)
@@ -765,7 +881,7 @@ func (f *formatter) binaryExpr(x *ast.BinaryExpr, prec1, cutoff, depth int) {
 	if printBlank {
 		f.print(blank)
 	}
-	f.expr1(x.Y, prec+1, depth+1)
+	f.expr1(x.Y, yPrec, depth+1)
 }
 
 func isBinary(expr ast.Expr) bool {
@@ -785,7 +901,11 @@ func (f *formatter) possibleSelectorExpr(expr ast.Expr, prec1, depth int) bool {
 // multiple lines.
 func (f *formatter) selectorExpr(x *ast.SelectorExpr, depth int) bool {
 	f.expr1(x.X, token.HighestPrec, depth)
-	f.print(token.PERIOD)
+	if x.Optional {
+		f.print(token.OPTPERIOD)
+	} else {
+		f.print(token.PERIOD)
+	}
 	if x.Sel.Pos().IsNewline() {
 		f.print(indent, formfeed)
 		f.expr(x.Sel.(ast.Expr))
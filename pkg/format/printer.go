@@ -118,6 +118,11 @@ func (p *printer) Print(v interface{}) {
 		impliedComma = true
 		p.lastTok = x.Kind
 
+	case *ast.Attribute:
+		data = x.Text
+		impliedComma = true
+		p.lastTok = token.ATTRIBUTE
+
 	case *ast.Ident:
 		data = x.Name
 		if !ast.IsValidIdent(data) {
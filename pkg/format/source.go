@@ -0,0 +1,482 @@
+package format
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/acorn-io/aml/pkg/ast"
+	"github.com/acorn-io/aml/pkg/parser"
+	"github.com/acorn-io/aml/pkg/token"
+)
+
+// Source is a parsed file along with the text it was parsed from. The File may be
+// edited in place and printed again with Format, which reproduces the original text,
+// including comments, blank lines and layout, for every node that was not changed and
+// only formats the nodes that were added or modified.
+type Source struct {
+	File *ast.File
+
+	src   []byte
+	file  *token.File
+	nodes map[ast.Node]*original
+}
+
+// original is the state of a node as it was parsed
+type original struct {
+	start, end int
+	token      string
+	children   []ast.Node
+}
+
+// ParseSource parses src and records it so that edits to the resulting file can be
+// printed without losing the formatting of the rest of the file.
+func ParseSource(filename string, src []byte, opt ...parser.Option) (*Source, error) {
+	f, err := parser.ParseFile(filename, bytes.NewReader(src), opt...)
+	if err != nil {
+		return nil, err
+	}
+	return NewSource(f, src), nil
+}
+
+// NewSource records the original text of file, which must have been parsed from src.
+func NewSource(file *ast.File, src []byte) *Source {
+	s := &Source{
+		File:  file,
+		src:   src,
+		nodes: map[ast.Node]*original{},
+	}
+	for _, decl := range file.Decls {
+		if f := decl.Pos().File(); f != nil {
+			s.file = f
+			break
+		}
+	}
+	s.record(file)
+	return s
+}
+
+func (s *Source) record(n ast.Node) {
+	children := children(n)
+	if start, end, ok := s.span(n); ok {
+		s.nodes[n] = &original{
+			start:    start,
+			end:      end,
+			token:    tokenOf(n),
+			children: children,
+		}
+	}
+	for _, child := range children {
+		if child != nil {
+			s.record(child)
+		}
+	}
+}
+
+// span returns the offsets of the text of the node in the source
+func (s *Source) span(n ast.Node) (int, int, bool) {
+	if _, ok := n.(*ast.File); ok {
+		return 0, len(s.src), true
+	}
+	pos, end := n.Pos(), n.End()
+	if s.file == nil || pos.File() != s.file || end.File() != s.file {
+		return 0, 0, false
+	}
+	start, stop := pos.Offset(), end.Offset()
+	return start, stop, start >= 0 && start <= stop && stop <= len(s.src)
+}
+
+// Format prints the current state of the File
+func (s *Source) Format() ([]byte, error) {
+	p := &sourcePrinter{
+		Source:  s,
+		changed: map[ast.Node]bool{},
+	}
+	if err := p.print(s.File, ""); err != nil {
+		return nil, err
+	}
+	return p.buf.Bytes(), nil
+}
+
+type sourcePrinter struct {
+	*Source
+
+	buf     bytes.Buffer
+	changed map[ast.Node]bool
+}
+
+// isChanged reports whether the node or any of its descendants differ from what was parsed
+func (p *sourcePrinter) isChanged(n ast.Node) bool {
+	if changed, ok := p.changed[n]; ok {
+		return changed
+	}
+
+	changed := true
+	if orig, ok := p.nodes[n]; ok && orig.token == tokenOf(n) {
+		children := children(n)
+		changed = len(children) != len(orig.children)
+		for i := 0; !changed && i < len(children); i++ {
+			changed = children[i] != orig.children[i] ||
+				children[i] != nil && p.isChanged(children[i])
+		}
+	}
+
+	p.changed[n] = changed
+	return changed
+}
+
+func (p *sourcePrinter) print(n ast.Node, indent string) error {
+	orig, ok := p.nodes[n]
+	switch {
+	case !ok || orig.token != tokenOf(n):
+		return p.format(n, indent)
+	case !p.isChanged(n):
+		p.buf.Write(p.src[orig.start:orig.end])
+		return nil
+	}
+
+	switch x := n.(type) {
+	case *ast.File:
+		return p.list(n, orig, declNodes(x.Decls), indent)
+	case *ast.StructLit:
+		if x.Lbrace.IsValid() && x.Rbrace.IsValid() {
+			return p.list(n, orig, declNodes(x.Elts), indent)
+		}
+		// structs without braces, as in a: b: 1, are only valid with one field so
+		// they are formatted with braces if fields are added
+		return p.fixed(n, orig, indent)
+	case *ast.ListLit:
+		return p.list(n, orig, exprNodes(x.Elts), indent)
+	}
+	return p.fixed(n, orig, indent)
+}
+
+// fixed prints a node whose children may have been changed but not added or removed,
+// copying the original text between the children.
+func (p *sourcePrinter) fixed(n ast.Node, orig *original, indent string) error {
+	type pair struct {
+		orig *original
+		node ast.Node
+	}
+
+	children := children(n)
+	if len(children) != len(orig.children) {
+		return p.format(n, indent)
+	}
+
+	var pairs []pair
+	for i, child := range children {
+		if (child == nil) != (orig.children[i] == nil) {
+			return p.format(n, indent)
+		} else if child == nil {
+			continue
+		}
+		childOrig, ok := p.nodes[orig.children[i]]
+		if !ok {
+			return p.format(n, indent)
+		}
+		pairs = append(pairs, pair{orig: childOrig, node: child})
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].orig.start < pairs[j].orig.start
+	})
+
+	offset := orig.start
+	for _, pair := range pairs {
+		if pair.orig.start < offset || pair.orig.end > orig.end {
+			return p.format(n, indent)
+		}
+		offset = pair.orig.end
+	}
+
+	offset = orig.start
+	for _, pair := range pairs {
+		p.buf.Write(p.src[offset:pair.orig.start])
+		if err := p.print(pair.node, p.lineIndent(pair.orig.start)); err != nil {
+			return err
+		}
+		offset = pair.orig.end
+	}
+	p.buf.Write(p.src[offset:orig.end])
+	return nil
+}
+
+// list prints a file, struct or list whose elements may have been added, removed or
+// reordered. The text between two elements up to the end of the line belongs to the
+// element before it, such as a trailing comma or comment, and the rest belongs to
+// the element after it, such as blank lines and doc comments.
+func (p *sourcePrinter) list(n ast.Node, orig *original, elems []ast.Node, indent string) error {
+	_, isFile := n.(*ast.File)
+	_, isList := n.(*ast.ListLit)
+
+	old := orig.children
+	if len(old) == 0 {
+		return p.format(n, indent)
+	}
+
+	open, closing := orig.start+1, orig.end-1
+	if isFile {
+		open, closing = 0, len(p.src)
+	}
+
+	var (
+		origs  = make([]*original, len(old))
+		index  = map[ast.Node]int{}
+		leads  = make([]string, len(old))
+		trails = make([]string, len(old))
+		offset = open
+	)
+	for i, elem := range old {
+		elemOrig, ok := p.nodes[elem]
+		if !ok || elemOrig.start < offset || elemOrig.end > closing {
+			return p.format(n, indent)
+		}
+		origs[i] = elemOrig
+		index[elem] = i
+
+		gap := string(p.src[offset:elemOrig.start])
+		if i > 0 {
+			trails[i-1], gap = splitLine(gap)
+		}
+		leads[i] = gap
+		offset = elemOrig.end
+	}
+
+	last := len(old) - 1
+	trails[last], _ = splitLine(string(p.src[origs[last].end:closing]))
+
+	multiline := isFile || strings.Contains(leads[0], "\n")
+	for _, lead := range leads[1:] {
+		multiline = multiline || strings.Contains(lead, "\n")
+	}
+
+	var (
+		sep        = ", "
+		elemIndent = indent
+	)
+	if multiline {
+		elemIndent = p.lineIndent(origs[last].start)
+	} else if len(old) > 1 {
+		sep = leads[1]
+	}
+
+	p.buf.Write(p.src[orig.start:open])
+	for i, elem := range elems {
+		var (
+			lead, trail string
+			k, isOld    = index[elem]
+		)
+
+		switch {
+		case i == 0 && isOld && k > 0 && multiline:
+			// the blank lines before the element separated it from the removed ones
+			lead = strings.TrimLeft(leads[k], "\n")
+			if !isFile {
+				lead = "\n" + lead
+			}
+		case i == 0:
+			lead = leads[0]
+		case isOld && k > 0:
+			lead = leads[k]
+		case multiline && isOld && strings.HasPrefix(leads[0], "\n"):
+			lead = leads[0]
+		case multiline && isOld:
+			lead = "\n" + leads[0]
+		case multiline:
+			lead = "\n" + elemIndent
+		default:
+			lead = sep
+		}
+
+		if isOld {
+			trail = trails[k]
+		} else if multiline && isList {
+			trail = ","
+		}
+
+		p.buf.WriteString(lead)
+		childIndent := elemIndent
+		if isOld {
+			childIndent = p.lineIndent(origs[k].start)
+		}
+		if err := p.print(elem, childIndent); err != nil {
+			return err
+		}
+		p.buf.WriteString(trail)
+	}
+
+	if len(elems) == 0 {
+		p.buf.Write(p.src[closing:orig.end])
+		return nil
+	}
+	p.buf.Write(p.src[origs[last].end+len(trails[last]) : orig.end])
+	return nil
+}
+
+// format prints a node that has no original text, indenting all lines after the first
+func (p *sourcePrinter) format(n ast.Node, indent string) error {
+	out, err := Node(n)
+	if err != nil {
+		return err
+	}
+	if _, ok := n.(*ast.File); ok {
+		p.buf.Write(out)
+		return nil
+	}
+
+	lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+	for i, line := range lines {
+		if i > 0 {
+			p.buf.WriteByte('\n')
+			if line != "" {
+				p.buf.WriteString(indent)
+			}
+		}
+		p.buf.WriteString(line)
+	}
+	return nil
+}
+
+// lineIndent returns the whitespace at the start of the line containing offset
+func (p *sourcePrinter) lineIndent(offset int) string {
+	start := bytes.LastIndexByte(p.src[:offset], '\n') + 1
+	end := start
+	for end < offset && (p.src[end] == ' ' || p.src[end] == '\t') {
+		end++
+	}
+	return string(p.src[start:end])
+}
+
+// splitLine splits s before the first newline. If there is no newline the first
+// result is empty.
+func splitLine(s string) (string, string) {
+	i := strings.IndexByte(s, '\n')
+	if i < 0 {
+		return "", s
+	}
+	return s[:i], s[i:]
+}
+
+// tokenOf returns the text of the tokens of a node that are not themselves nodes
+func tokenOf(n ast.Node) string {
+	switch x := n.(type) {
+	case *ast.Ident:
+		return x.Name
+	case *ast.BasicLit:
+		return x.Kind.String() + " " + x.Value
+	case *ast.Attribute:
+		return x.Text
+	case *ast.UnaryExpr:
+		return x.Op.String()
+	case *ast.BinaryExpr:
+		return x.Op.String()
+	case *ast.Field:
+		return x.Constraint.String() + " " + strconv.FormatBool(x.Match.IsValid())
+	case *ast.SelectorExpr:
+		return strconv.FormatBool(x.Optional)
+	case *ast.IndexExpr:
+		return strconv.FormatBool(x.Optional)
+	}
+	return ""
+}
+
+func declNodes(decls []ast.Decl) (result []ast.Node) {
+	for _, decl := range decls {
+		result = append(result, decl)
+	}
+	return
+}
+
+func exprNodes(exprs []ast.Expr) (result []ast.Node) {
+	for _, expr := range exprs {
+		result = append(result, expr)
+	}
+	return
+}
+
+func clauseNodes(clauses []ast.Clause) (result []ast.Node) {
+	for _, clause := range clauses {
+		result = append(result, clause)
+	}
+	return
+}
+
+// optional converts a possibly nil child to a node that is nil if the child is
+func optional[T comparable](n T) ast.Node {
+	var zero T
+	if n == zero {
+		return nil
+	}
+	return any(n).(ast.Node)
+}
+
+// children returns the direct children of a node. Optional children that are not
+// set are returned as nil so that the number of children only changes for lists.
+func children(n ast.Node) []ast.Node {
+	switch x := n.(type) {
+	case *ast.File:
+		return declNodes(x.Decls)
+	case *ast.Field:
+		result := append([]ast.Node{optional(x.Label)}, exprNodes(x.Bases)...)
+		result = append(result, optional(x.Value))
+		for _, attr := range x.Attrs {
+			result = append(result, attr)
+		}
+		return result
+	case *ast.Func:
+		return []ast.Node{optional(x.ReturnType), optional(x.Body)}
+	case *ast.Lambda:
+		var result []ast.Node
+		for _, ident := range x.Idents {
+			result = append(result, ident)
+		}
+		return append(result, optional(x.Expr))
+	case *ast.StructLit:
+		return declNodes(x.Elts)
+	case *ast.SchemaLit:
+		return []ast.Node{optional(x.Decl)}
+	case *ast.ListLit:
+		return exprNodes(x.Elts)
+	case *ast.ListComprehension:
+		return append(append([]ast.Node{optional(x.Clause)}, clauseNodes(x.Clauses)...), optional(x.Value))
+	case *ast.Interpolation:
+		return exprNodes(x.Elts)
+	case *ast.For:
+		return append(append([]ast.Node{optional(x.Clause)}, clauseNodes(x.Clauses)...), optional(x.Struct), optional(x.Else))
+	case *ast.ForClause:
+		return []ast.Node{optional(x.Key), optional(x.Value), optional(x.Pattern), optional(x.Source)}
+	case *ast.Spread:
+		return []ast.Node{optional(x.X)}
+	case *ast.ConditionalExpr:
+		return []ast.Node{optional(x.Condition), optional(x.X), optional(x.Y)}
+	case *ast.If:
+		return []ast.Node{optional(x.Condition), optional(x.Struct), optional(x.Else)}
+	case *ast.Else:
+		return []ast.Node{optional(x.If), optional(x.Struct)}
+	case *ast.IfClause:
+		return []ast.Node{optional(x.Condition)}
+	case *ast.LetClause:
+		return []ast.Node{optional(x.Ident), optional(x.Pattern), optional(x.Expr)}
+	case *ast.ParenExpr:
+		return []ast.Node{optional(x.X)}
+	case *ast.DefaultExpr:
+		return []ast.Node{optional(x.X)}
+	case *ast.SelectorExpr:
+		return []ast.Node{optional(x.X), optional(x.Sel)}
+	case *ast.IndexExpr:
+		return []ast.Node{optional(x.X), optional(x.Index)}
+	case *ast.SliceExpr:
+		return []ast.Node{optional(x.X), optional(x.Low), optional(x.High)}
+	case *ast.CallExpr:
+		return append([]ast.Node{optional(x.Fun)}, declNodes(x.Args)...)
+	case *ast.UnaryExpr:
+		return []ast.Node{optional(x.X)}
+	case *ast.BinaryExpr:
+		return []ast.Node{optional(x.X), optional(x.Y)}
+	case *ast.EmbedDecl:
+		return []ast.Node{optional(x.Expr)}
+	}
+	return nil
+}
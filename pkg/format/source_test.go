@@ -0,0 +1,157 @@
+package format
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/acorn-io/aml/pkg/ast"
+	"github.com/acorn-io/aml/pkg/token"
+	"github.com/hexops/autogold/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceRoundTrip(t *testing.T) {
+	files, err := filepath.Glob("testdata/*/*.acorn")
+	require.NoError(t, err)
+
+	for _, file := range files {
+		t.Run(strings.TrimSuffix(filepath.Base(file), ".acorn"), func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			require.NoError(t, err)
+
+			src, err := ParseSource(file, data)
+			if err != nil {
+				t.Skip(err)
+			}
+
+			out, err := src.Format()
+			require.NoError(t, err)
+			require.Equal(t, string(data), string(out))
+		})
+	}
+}
+
+func field(decls []ast.Decl, name string) *ast.Field {
+	for _, decl := range decls {
+		if f, ok := decl.(*ast.Field); ok {
+			if ident, ok := f.Label.(*ast.Ident); ok && ident.Name == name {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+func args(src *Source) *ast.StructLit {
+	return field(src.File.Decls, "args").Value.(*ast.StructLit)
+}
+
+func TestSourceEdit(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(src *Source)
+	}{
+		{
+			name: "replace-value",
+			edit: func(src *Source) {
+				field(args(src).Elts, "image").Value = &ast.BasicLit{Kind: token.STRING, Value: `"nginx:1.25"`}
+			},
+		},
+		{
+			name: "edit-literal",
+			edit: func(src *Source) {
+				field(args(src).Elts, "replicas").Value.(*ast.BasicLit).Value = "3"
+			},
+		},
+		{
+			name: "delete-field",
+			edit: func(src *Source) {
+				s := args(src)
+				s.Elts = append(s.Elts[:1], s.Elts[2:]...)
+			},
+		},
+		{
+			name: "delete-first-field",
+			edit: func(src *Source) {
+				s := args(src)
+				s.Elts = s.Elts[1:]
+			},
+		},
+		{
+			name: "delete-decl",
+			edit: func(src *Source) {
+				src.File.Decls = src.File.Decls[1:]
+			},
+		},
+		{
+			name: "add-field",
+			edit: func(src *Source) {
+				s := args(src)
+				s.Elts = append(s.Elts, &ast.Field{
+					Label: &ast.Ident{Name: "labels"},
+					Value: &ast.StructLit{Elts: []ast.Decl{
+						&ast.Field{
+							Label: &ast.Ident{Name: "app"},
+							Value: &ast.BasicLit{Kind: token.STRING, Value: `"web"`},
+						},
+					}},
+				})
+			},
+		},
+		{
+			name: "append-list",
+			edit: func(src *Source) {
+				ports := field(args(src).Elts, "ports").Value.(*ast.ListLit)
+				ports.Elts = append(ports.Elts, &ast.BasicLit{Kind: token.NUMBER, Value: "8080"})
+				env := field(args(src).Elts, "env").Value.(*ast.ListLit)
+				env.Elts = append(env.Elts, &ast.BasicLit{Kind: token.STRING, Value: `"C=3"`})
+			},
+		},
+		{
+			name: "delete-list-elements",
+			edit: func(src *Source) {
+				ports := field(args(src).Elts, "ports").Value.(*ast.ListLit)
+				ports.Elts = ports.Elts[1:]
+				env := field(args(src).Elts, "env").Value.(*ast.ListLit)
+				env.Elts = env.Elts[:1]
+			},
+		},
+		{
+			name: "add-to-implicit-struct",
+			edit: func(src *Source) {
+				web := field(src.File.Decls, "containers").Value.(*ast.StructLit)
+				web.Elts = append(web.Elts, &ast.Field{
+					Label: &ast.Ident{Name: "db"},
+					Value: &ast.StructLit{},
+				})
+			},
+		},
+		{
+			name: "add-decl",
+			edit: func(src *Source) {
+				src.File.Decls = append(src.File.Decls, &ast.Field{
+					Label: &ast.Ident{Name: "version"},
+					Value: &ast.BasicLit{Kind: token.NUMBER, Value: "2"},
+				})
+			},
+		},
+	}
+
+	data, err := os.ReadFile("testdata/TestSourceEdit/input.acorn")
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := ParseSource("input.acorn", data)
+			require.NoError(t, err)
+
+			tt.edit(src)
+
+			out, err := src.Format()
+			require.NoError(t, err)
+			autogold.ExpectFile(t, autogold.Raw(out))
+		})
+	}
+}
@@ -21,15 +21,40 @@ type Schema struct {
 
 // +k8s:openapi-gen=true
 type Property struct {
-	Description string `json:",omitempty"`
-	Type        string `json:"type,omitempty"`
-	Ref         string `json:"$ref,omitempty"`
+	Description string            `json:",omitempty"`
+	Type        string            `json:"type,omitempty"`
+	Ref         string            `json:"$ref,omitempty"`
+	Comment     string            `json:"$comment,omitempty"`
+	Deprecated  bool              `json:"deprecated,omitempty"`
+	Examples    []json.RawMessage `json:"examples,omitempty"`
 
 	//Const Any   `json:"const,omitempty"`
 	//Enum  []Any `json:"enum,omitempty"`
 
+	// For strings
+	MinLength *int64 `json:"minLength,omitempty"`
+	MaxLength *int64 `json:"maxLength,omitempty"`
+
 	// For arrays
-	Items []Schema `json:"items,omitempty"`
+	Items       []Schema `json:"items,omitempty"`
+	MinItems    *int64   `json:"minItems,omitempty"`
+	MaxItems    *int64   `json:"maxItems,omitempty"`
+	UniqueItems bool     `json:"uniqueItems,omitempty"`
+
+	// For objects
+	MinProperties *int64 `json:"minProperties,omitempty"`
+	MaxProperties *int64 `json:"maxProperties,omitempty"`
+
+	// For unions
+	OneOf         []Schema       `json:"oneOf,omitempty"`
+	Discriminator *Discriminator `json:"discriminator,omitempty"`
+}
+
+// Discriminator is the property that selects which of the oneOf schemas applies
+// +k8s:openapi-gen=true
+type Discriminator struct {
+	PropertyName string            `json:"propertyName"`
+	Mapping      map[string]string `json:"mapping,omitempty"`
 }
 
 type Type []string
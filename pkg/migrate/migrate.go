@@ -0,0 +1,262 @@
+// Package migrate converts documents from one version of a schema to another. The
+// versions and the migrations between them are declared in AML as in
+//
+//	define V1: {name: string, port: number}
+//	define V2: {version: "v2", name: string, ports: [number]}
+//
+//	versions: {
+//		v1: V1
+//		v2: V2
+//	}
+//
+//	migrations: [{
+//		from: "v1"
+//		to:   "v2"
+//		migrate: function {
+//			args: doc: object
+//			return: {version: "v2", name: args.doc.name, ports: [args.doc.port]}
+//		}
+//	}]
+//
+// The versions are listed oldest first. The version of a document is the value of its
+// version field, or else the newest version whose schema the document matches.
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/acorn-io/aml/pkg/value"
+)
+
+// DefaultVersionField is the field of a document that holds its version unless the
+// migrations set versionField
+const DefaultVersionField = "version"
+
+// Versions are the versions of a schema and the migrations between them
+type Versions struct {
+	// Names are the names of the versions, oldest first
+	Names []string
+	// Schemas are the schemas of the versions by name
+	Schemas map[string]value.Value
+	// Migrations are the functions that convert a document from one version to another
+	Migrations []Migration
+	// VersionField is the field of a document that holds its version
+	VersionField string
+}
+
+// Migration is a function that converts a document of version From to version To
+type Migration struct {
+	From string
+	To   string
+	Func value.Value
+}
+
+// Load reads the versions and migrations from the value of an AML file that declares
+// them
+func Load(v value.Value) (*Versions, error) {
+	result := &Versions{
+		Schemas:      map[string]value.Value{},
+		VersionField: DefaultVersionField,
+	}
+
+	if field, ok, err := value.Lookup(v, value.NewValue("versionField")); err != nil {
+		return nil, err
+	} else if ok {
+		result.VersionField, err = value.ToString(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid versionField: %w", err)
+		}
+	}
+
+	versions, ok, err := value.Lookup(v, value.NewValue("versions"))
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, fmt.Errorf("missing field versions")
+	}
+
+	result.Names, err = value.Keys(versions)
+	if err != nil {
+		return nil, fmt.Errorf("invalid versions: %w", err)
+	} else if len(result.Names) == 0 {
+		return nil, fmt.Errorf("invalid versions: at least one version is required")
+	}
+
+	for _, name := range result.Names {
+		schema, _, err := value.Lookup(versions, value.NewValue(name))
+		if err != nil {
+			return nil, err
+		}
+		if schema.Kind() != value.SchemaKind {
+			return nil, fmt.Errorf("invalid version %s: expected a schema, got kind %s", name, schema.Kind())
+		}
+		result.Schemas[name] = schema
+	}
+
+	migrations, ok, err := value.Lookup(v, value.NewValue("migrations"))
+	if err != nil || !ok {
+		return result, err
+	}
+
+	items, err := value.ToValueArray(migrations)
+	if err != nil {
+		return nil, fmt.Errorf("invalid migrations: %w", err)
+	}
+
+	for i, item := range items {
+		migration, err := result.toMigration(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration %d: %w", i, err)
+		}
+		result.Migrations = append(result.Migrations, migration)
+	}
+
+	return result, nil
+}
+
+func (v *Versions) toMigration(item value.Value) (result Migration, _ error) {
+	for _, field := range []struct {
+		key    string
+		target *string
+	}{
+		{key: "from", target: &result.From},
+		{key: "to", target: &result.To},
+	} {
+		name, ok, err := value.Lookup(item, value.NewValue(field.key))
+		if err != nil {
+			return result, err
+		} else if !ok {
+			return result, fmt.Errorf("missing field %s", field.key)
+		}
+		*field.target, err = value.ToString(name)
+		if err != nil {
+			return result, err
+		}
+		if _, ok := v.Schemas[*field.target]; !ok {
+			return result, fmt.Errorf("unknown version %q", *field.target)
+		}
+	}
+
+	fn, ok, err := value.Lookup(item, value.NewValue("migrate"))
+	if err != nil {
+		return result, err
+	} else if !ok {
+		return result, fmt.Errorf("missing field migrate")
+	} else if fn.Kind() != value.FuncKind {
+		return result, fmt.Errorf("migrate must be a function, got kind %s", fn.Kind())
+	}
+	result.Func = fn
+	return result, nil
+}
+
+// Latest returns the name of the newest version
+func (v *Versions) Latest() string {
+	return v.Names[len(v.Names)-1]
+}
+
+// Detect returns the version of the document data
+func (v *Versions) Detect(ctx context.Context, data value.Value) (string, error) {
+	if data.Kind() == value.ObjectKind {
+		field, ok, err := value.Lookup(data, value.NewValue(v.VersionField))
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			if name, err := value.ToString(field); err == nil {
+				if _, ok := v.Schemas[name]; !ok {
+					return "", fmt.Errorf("unknown version %q in field %s", name, v.VersionField)
+				}
+				return name, nil
+			}
+		}
+	}
+
+	for i := len(v.Names) - 1; i >= 0; i-- {
+		if _, err := value.Validate(ctx, v.Schemas[v.Names[i]], data); err == nil {
+			return v.Names[i], nil
+		}
+	}
+
+	return "", fmt.Errorf("can not detect the version of the document, it does not match the schema of any version")
+}
+
+// Path returns the migrations that convert a document of version from to version to, in
+// the order they are applied
+func (v *Versions) Path(from, to string) ([]Migration, error) {
+	for _, name := range []string{from, to} {
+		if _, ok := v.Schemas[name]; !ok {
+			return nil, fmt.Errorf("unknown version %q", name)
+		}
+	}
+
+	// breadth first search for the shortest path, previous is the migration used to
+	// reach each version
+	previous := map[string]int{from: -1}
+	queue := []string{from}
+	for len(queue) > 0 && queue[0] != to {
+		current := queue[0]
+		queue = queue[1:]
+		for i, migration := range v.Migrations {
+			if _, seen := previous[migration.To]; migration.From == current && !seen {
+				previous[migration.To] = i
+				queue = append(queue, migration.To)
+			}
+		}
+	}
+
+	if _, ok := previous[to]; !ok {
+		return nil, fmt.Errorf("no migration from version %s to %s", from, to)
+	}
+
+	var result []Migration
+	for current := to; current != from; {
+		migration := v.Migrations[previous[current]]
+		result = append(result, migration)
+		current = migration.From
+	}
+	slices.Reverse(result)
+	return result, nil
+}
+
+// Migrate converts the document data of version from to version to and validates the
+// result against the schema of to. If from is empty the version of data is detected. The
+// returned document does not have the defaults of the schema applied so that it can be
+// written back as the source of the document.
+func (v *Versions) Migrate(ctx context.Context, data value.Value, from, to string) (_ value.Value, detected string, _ error) {
+	if from == "" {
+		var err error
+		from, err = v.Detect(ctx, data)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	if to == "" {
+		to = v.Latest()
+	}
+
+	migrations, err := v.Path(from, to)
+	if err != nil {
+		return nil, from, err
+	}
+
+	for _, migration := range migrations {
+		result, ok, err := value.Call(ctx, migration.Func, value.CallArgument{
+			Positional: true,
+			Value:      data,
+		})
+		if err != nil {
+			return nil, from, fmt.Errorf("migrating from version %s to %s: %w", migration.From, migration.To, err)
+		} else if !ok {
+			return nil, from, fmt.Errorf("migrating from version %s to %s: migration did not return a value", migration.From, migration.To)
+		}
+		data = result
+	}
+
+	if _, err := value.Validate(ctx, v.Schemas[to], data); err != nil {
+		return nil, from, fmt.Errorf("document migrated from version %s is not valid for version %s: %w", from, to, err)
+	}
+
+	return data, from, nil
+}
@@ -0,0 +1,107 @@
+package migrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/acorn-io/aml/pkg/ast"
+	"github.com/acorn-io/aml/pkg/edit"
+	"github.com/acorn-io/aml/pkg/value"
+)
+
+// Rewrite changes the AML source src of the document before so that it evaluates to after.
+// Only the fields that changed are rewritten, so the comments and formatting of the rest
+// of the source are kept. An error is returned if a changed field is not written as a
+// field in src, for example if it is the result of an embedded expression.
+func Rewrite(src []byte, before, after value.Value) ([]byte, error) {
+	if before.Kind() != value.ObjectKind || after.Kind() != value.ObjectKind {
+		return nil, fmt.Errorf("can only rewrite a document that is an object")
+	}
+	return rewriteObject(src, "", before, after)
+}
+
+func rewriteObject(src []byte, path string, before, after value.Value) ([]byte, error) {
+	beforeKeys, err := value.Keys(before)
+	if err != nil {
+		return nil, err
+	}
+	afterKeys, err := value.Keys(after)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range beforeKeys {
+		if _, ok, err := value.Lookup(after, value.NewValue(key)); err != nil {
+			return nil, err
+		} else if !ok {
+			src, err = edit.Delete(src, fieldPath(path, key))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, key := range afterKeys {
+		afterValue, _, err := value.Lookup(after, value.NewValue(key))
+		if err != nil {
+			return nil, err
+		}
+		beforeValue, ok, err := value.Lookup(before, value.NewValue(key))
+		if err != nil {
+			return nil, err
+		}
+		if ok && beforeValue.Kind() == value.ObjectKind && afterValue.Kind() == value.ObjectKind {
+			src, err = rewriteObject(src, fieldPath(path, key), beforeValue, afterValue)
+		} else if !ok {
+			src, err = rewriteValue(src, fieldPath(path, key), nil, afterValue)
+		} else {
+			src, err = rewriteValue(src, fieldPath(path, key), beforeValue, afterValue)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return src, nil
+}
+
+// rewriteValue sets the field at path to after if it is not equal to before, which is
+// nil if the field is new
+func rewriteValue(src []byte, path string, before, after value.Value) ([]byte, error) {
+	afterData, err := toJSON(after)
+	if err != nil {
+		return nil, err
+	}
+	if before != nil {
+		beforeData, err := toJSON(before)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(beforeData, afterData) {
+			return src, nil
+		}
+	}
+	// JSON is valid AML
+	return edit.Set(src, path, string(afterData))
+}
+
+func toJSON(v value.Value) ([]byte, error) {
+	nv, _, err := value.NativeValue(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(nv)
+}
+
+// fieldPath returns the edit path of the field key in the struct at path
+func fieldPath(path, key string) string {
+	if !ast.IsValidIdent(key) {
+		key = strconv.Quote(key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
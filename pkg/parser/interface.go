@@ -34,6 +34,15 @@ var (
 	traceOpt        = func(p *parser) {
 		p.mode |= traceMode
 	}
+
+	// Tolerant causes ParseFile to return the best-effort AST, which may contain
+	// ast.BadExpr and ast.BadDecl nodes, along with all errors found rather than
+	// a nil file. This is intended for editors, linters and formatters. The bad
+	// nodes keep the source text they cover so that it can be printed unchanged.
+	Tolerant    Option = tolerantOpt
+	tolerantOpt        = func(p *parser) {
+		p.mode |= tolerantMode
+	}
 )
 
 // A mode value is a set of flags (or 0).
@@ -44,6 +53,7 @@ type mode uint
 const (
 	parseCommentsMode mode = 1 << iota // parse comments and add them to AST
 	traceMode                          // print a trace of parsed productions
+	tolerantMode                       // return partial ASTs along with errors
 )
 
 func ParseFile(filename string, src io.Reader, mode ...Option) (retFile *ast.File, retErr error) {
@@ -59,9 +69,15 @@ func ParseFile(filename string, src io.Reader, mode ...Option) (retFile *ast.Fil
 		}
 	)
 
+	var errs []error
+
 	defer func() {
 		if pp.panicking {
 			_ = recover()
+			if pp.mode&tolerantMode != 0 {
+				result.Decls = append(result.Decls, pp.decls...)
+				retFile, retErr = &result, errors.Join(append(errs, pp.errors...)...)
+			}
 		}
 
 		// set result values
@@ -84,19 +100,20 @@ func ParseFile(filename string, src io.Reader, mode ...Option) (retFile *ast.Fil
 		// parse source
 		pp.init(entry.Filename, entry.Data, mode)
 		f := pp.parseFile()
-		if f == nil {
-			return nil, errors.Join(pp.errors...)
-		}
 
-		if len(pp.errors) > 0 {
+		if pp.mode&tolerantMode != 0 {
+			errs = append(errs, pp.errors...)
+		} else if f == nil || len(pp.errors) > 0 {
 			return nil, errors.Join(pp.errors...)
 		}
 
-		result.Decls = append(result.Decls, f.Decls...)
-		result.SetComments(append(result.Comments(), f.Comments()...))
+		if f != nil {
+			result.Decls = append(result.Decls, f.Decls...)
+			result.SetComments(append(result.Comments(), f.Comments()...))
+		}
 	}
 
-	return &result, nil
+	return &result, errors.Join(errs...)
 }
 
 func ParseExpr(filename string, src io.Reader, mode ...Option) (_ ast.Expr, retErr error) {
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/acorn-io/aml/pkg/ast"
 	"github.com/acorn-io/aml/pkg/errors"
@@ -12,6 +13,7 @@ import (
 // The parser structure holds the parser's internal state.
 type parser struct {
 	file    *token.File
+	src     []byte
 	offset  int
 	errors  []error
 	scanner scanner.Scanner
@@ -22,6 +24,10 @@ type parser struct {
 	panicking bool // set if we are bailing out due to too many errors.
 	indent    int  // indentation used for tracing output
 
+	// decls are the top-level declarations of the file parsed so far, which are
+	// returned in tolerant mode if the parser bails out
+	decls []ast.Decl
+
 	// Comments
 	leadComment *ast.CommentGroup
 	comments    *commentState
@@ -30,6 +36,7 @@ type parser struct {
 	pos token.Pos   // token position
 	tok token.Token // one token look-ahead
 	lit string      // token literal
+	bad bool        // set if the scanner reported an error for the token
 
 	// Error recovery
 	// (used to limit the number of calls to syncXXX functions
@@ -48,6 +55,7 @@ func (p *parser) init(filename string, src []byte, mode []Option) {
 		f(p)
 	}
 	p.file = token.NewFile(filename, p.offset, len(src))
+	p.src = src
 
 	var m scanner.Mode
 	if p.mode&parseCommentsMode != 0 {
@@ -244,12 +252,18 @@ func (p *parser) next0() {
 		}
 	}
 
+	errs := len(p.errors)
 	p.pos, p.tok, p.lit = p.scanner.Scan()
+	p.bad = len(p.errors) > errs
 }
 
 // Consume a comment and return it and the line on which it ends.
 func (p *parser) consumeComment() (comment *ast.Comment, endline int) {
 	endline = p.file.Line(p.pos)
+	if p.lit[1] == '*' {
+		// a /*-style comment may span several lines
+		endline += strings.Count(p.lit, "\n")
+	}
 	comment = &ast.Comment{Slash: p.pos, Text: p.lit}
 	p.next0()
 
@@ -337,9 +351,10 @@ func (p *parser) next() {
 
 		}
 
-		if endline+1 == p.file.Line(p.pos) && p.tok != token.EOF {
+		if line := p.file.Line(p.pos); (endline+1 == line || endline == line) && p.tok != token.EOF {
 			// The next token is following on the line immediately after the
-			// comment group, thus the last comment group is a lead comment.
+			// comment group, or on the same line as a block comment, thus the
+			// last comment group is a lead comment.
 			comment.Doc = true
 			p.leadComment = comment
 		} else {
@@ -388,7 +403,13 @@ func (p *parser) expectClosing(tok token.Token, context string) token.Pos {
 		p.errf(p.pos, "missing ',' before newline in %s", context)
 		p.next()
 	}
-	return p.expect(tok)
+	missing := p.tok != tok
+	pos := p.expect(tok)
+	if missing && p.mode&tolerantMode != 0 {
+		// the token is added when formatting a partial AST, on a line of its own
+		pos = p.file.Pos(p.file.Offset(p.safePos(pos)), token.Newline)
+	}
+	return pos
 }
 
 func (p *parser) expectComma() {
@@ -468,7 +489,7 @@ func syncExpr(p *parser) {
 func (p *parser) safePos(pos token.Pos) (res token.Pos) {
 	defer func() {
 		if recover() != nil {
-			res = p.file.Pos(p.file.Base()+p.file.Size(), pos.RelPos()) // EOF position
+			res = p.file.Pos(p.file.Size(), pos.RelPos()) // EOF position
 		}
 	}()
 	_ = p.file.Offset(pos) // trigger a panic if position is out-of-range
@@ -537,7 +558,27 @@ func (p *parser) badExpr(fromPos token.Pos) (expr ast.Expr) {
 	defer func() { c.closeNode(p, expr) }()
 
 	p.errf(fromPos, "invalid expression")
-	return &ast.BadExpr{From: fromPos, To: p.pos}
+	switch {
+	case p.mode&tolerantMode == 0:
+	case p.tok == token.RBRACE, p.tok == token.RBRACK, p.tok == token.COMMA, p.tok == token.EOF:
+	default:
+		// consume the token so that it is part of the text of the bad expression
+		p.next()
+	}
+	return &ast.BadExpr{From: fromPos, To: p.pos, Text: p.sourceText(fromPos, p.pos)}
+}
+
+// sourceText returns the source between from and to without trailing white space. It
+// is recorded for bad nodes so that a partial AST can be printed without losing text.
+func (p *parser) sourceText(from, to token.Pos) string {
+	if from.File() != p.file || to.File() != p.file {
+		return ""
+	}
+	start, end := p.file.Offset(p.safePos(from)), p.file.Offset(p.safePos(to))
+	if start >= end || end > len(p.src) {
+		return ""
+	}
+	return strings.TrimRight(string(p.src[start:end]), " \t\r\n")
 }
 
 func (p *parser) parseDefault() (expr ast.Expr) {
@@ -586,6 +627,15 @@ func (p *parser) parseOperand() (expr ast.Expr) {
 		return p.parseDefault()
 
 	case token.NULL, token.TRUE, token.FALSE, token.NUMBER, token.STRING:
+		if p.bad {
+			// the literal is invalid, such as an unterminated string
+			c := p.openComments()
+			pos := p.pos
+			p.next()
+			expr := &ast.BadExpr{From: pos, To: p.pos, Text: p.sourceText(pos, p.pos)}
+			c.closeNode(p, expr)
+			return expr
+		}
 		return p.parseLiteral()
 
 	case token.INTERPOLATION:
@@ -598,7 +648,11 @@ func (p *parser) parseOperand() (expr ast.Expr) {
 		return p.parseFor()
 
 	case token.IF:
-		return p.parseIf()
+		return p.parseIfOrConditional()
+
+	case token.ELLIPSIS:
+		p.errf(p.pos, "%s is only allowed in a struct or list literal", token.ELLIPSIS)
+		return p.parseSpread()
 	}
 
 	return p.badExpr(p.pos)
@@ -614,7 +668,9 @@ func (p *parser) parseIndexOrSlice(x ast.Expr) (expr ast.Expr) {
 	c.pos = 1
 
 	const N = 2
-	lbrack := p.expect(token.LBRACK)
+	optional := p.tok == token.OPTLBRACK
+	lbrack := p.pos
+	p.next()
 
 	var index [N]ast.Expr
 	var colons [N - 1]token.Pos
@@ -633,6 +689,9 @@ func (p *parser) parseIndexOrSlice(x ast.Expr) (expr ast.Expr) {
 	rbrack := p.expect(token.RBRACK)
 
 	if nColons > 0 {
+		if optional {
+			p.errf(lbrack, "expected index after %s, slices can not be optional", token.OPTLBRACK)
+		}
 		return &ast.SliceExpr{
 			X:      x,
 			Lbrack: lbrack,
@@ -642,10 +701,11 @@ func (p *parser) parseIndexOrSlice(x ast.Expr) (expr ast.Expr) {
 	}
 
 	return &ast.IndexExpr{
-		X:      x,
-		Lbrack: lbrack,
-		Index:  index[0],
-		Rbrack: rbrack}
+		X:        x,
+		Lbrack:   lbrack,
+		Index:    index[0],
+		Rbrack:   rbrack,
+		Optional: optional}
 }
 
 func (p *parser) parseCall(fun ast.Expr) (expr *ast.CallExpr) {
@@ -685,6 +745,13 @@ func (p *parser) parseCallArgs() (list []ast.Decl) {
 }
 
 func (p *parser) parseFieldList() (list []ast.Decl) {
+	p.parseFieldListInto(&list)
+	return
+}
+
+// parseFieldListInto appends the declarations to list as they are parsed, so that the
+// declarations before a bail out are kept
+func (p *parser) parseFieldListInto(list *[]ast.Decl) {
 	if p.trace {
 		defer un(trace(p, "FieldList"))
 	}
@@ -692,10 +759,8 @@ func (p *parser) parseFieldList() (list []ast.Decl) {
 	defer p.closeList()
 
 	for p.tok != token.RBRACE && p.tok != token.EOF {
-		list = append(list, p.parseDecl())
+		*list = append(*list, p.parseDecl())
 	}
-
-	return
 }
 
 func (p *parser) parseLetDecl() (decl ast.Decl) {
@@ -707,16 +772,93 @@ func (p *parser) parseLetDecl() (decl ast.Decl) {
 	defer func() { c.closeNode(p, decl) }()
 
 	letPos := p.expect(token.LET)
-	ident := p.parseIdent()
-	assign := p.expect(token.COLON)
-	expr := p.parseRHS()
+	result := &ast.LetClause{
+		Let: letPos,
+	}
+	pattern := p.parsePattern()
+	if ident, ok := pattern.(*ast.Ident); ok {
+		result.Ident = ident
+	} else {
+		result.Pattern = pattern
+	}
+	result.Colon = p.expect(token.COLON)
+	result.Expr = p.parseRHS()
+	return result
+}
 
-	return &ast.LetClause{
-		Let:   letPos,
-		Ident: ident,
-		Colon: assign,
-		Expr:  expr,
+// parsePattern parses a name, or a struct or list of patterns that destructures a
+// value, such as {name, port: p} or [host, [a, b]]
+func (p *parser) parsePattern() ast.Expr {
+	if p.trace {
+		defer un(trace(p, "Pattern"))
 	}
+
+	switch p.tok {
+	case token.LBRACE:
+		return p.parseStructPattern()
+	case token.LBRACK:
+		return p.parseListPattern()
+	}
+	return p.parseIdent()
+}
+
+func (p *parser) parseStructPattern() *ast.StructLit {
+	c := p.openComments()
+	result := &ast.StructLit{
+		Lbrace: p.expect(token.LBRACE),
+	}
+	defer func() { c.closeNode(p, result) }()
+
+	for p.tok != token.RBRACE && p.tok != token.EOF {
+		var label ast.Label
+		if p.tok == token.STRING {
+			label = p.parseLiteral()
+		} else {
+			label = p.parseIdent()
+		}
+
+		if p.tok == token.COLON {
+			colon := p.expect(token.COLON)
+			result.Elts = append(result.Elts, &ast.Field{
+				Label: label,
+				Colon: colon,
+				Value: p.parsePattern(),
+			})
+		} else if ident, ok := label.(*ast.Ident); ok {
+			result.Elts = append(result.Elts, &ast.EmbedDecl{
+				Expr: ident,
+			})
+		} else {
+			p.errf(p.pos, "expected %s after quoted key in pattern", token.COLON)
+		}
+
+		if !p.atComma("struct pattern", token.RBRACE) {
+			break
+		}
+		p.next()
+	}
+
+	result.Rbrace = p.expectClosing(token.RBRACE, "struct pattern")
+	return result
+}
+
+func (p *parser) parseListPattern() *ast.ListLit {
+	c := p.openComments()
+	result := &ast.ListLit{
+		Lbrack: p.expect(token.LBRACK),
+	}
+	defer func() { c.closeNode(p, result) }()
+
+	for p.tok != token.RBRACK && p.tok != token.EOF {
+		result.Elts = append(result.Elts, p.parsePattern())
+		if !p.atComma("list pattern", token.RBRACK) {
+			break
+		}
+		p.next()
+	}
+
+	result.Rbrack = p.expectClosing(token.RBRACK, "list pattern")
+	return result
 }
 
 func (p *parser) parseElse() (expr *ast.Else) {
@@ -750,6 +892,46 @@ func (p *parser) parseElse() (expr *ast.Else) {
 	}
 }
 
+// parseIfOrConditional parses an if expression, or a conditional expression if the
+// condition is followed by then
+func (p *parser) parseIfOrConditional() (expr ast.Expr) {
+	if p.trace {
+		defer un(trace(p, "IfOrConditional"))
+	}
+
+	c := p.openComments()
+	defer func() { c.closeNode(p, expr) }()
+
+	ifPos := p.expect(token.IF)
+	clause := p.parseIfClause()
+	if !p.atThen() {
+		return p.parseIfBody(ifPos, clause)
+	}
+	return p.parseConditional(ifPos, clause)
+}
+
+func (p *parser) atThen() bool {
+	return p.tok == token.IDENT && p.lit == "then"
+}
+
+// parseConditional parses the then and else branches of a conditional expression
+func (p *parser) parseConditional(ifPos token.Pos, clause *ast.IfClause) *ast.ConditionalExpr {
+	thenPos := p.pos
+	p.next()
+	x := p.parseRHS()
+	elsePos := p.expect(token.ELSE)
+	y := p.parseRHS()
+
+	return &ast.ConditionalExpr{
+		If:        ifPos,
+		Condition: clause.Condition,
+		Then:      thenPos,
+		X:         x,
+		Else:      elsePos,
+		Y:         y,
+	}
+}
+
 func (p *parser) parseIf() (expr *ast.If) {
 	if p.trace {
 		defer un(trace(p, "If"))
@@ -758,9 +940,12 @@ func (p *parser) parseIf() (expr *ast.If) {
 	c := p.openComments()
 	defer func() { c.closeNode(p, expr) }()
 
+	ifPos := p.expect(token.IF)
+	return p.parseIfBody(ifPos, p.parseIfClause())
+}
+
+func (p *parser) parseIfBody(ifPos token.Pos, clause *ast.IfClause) *ast.If {
 	var (
-		ifPos      = p.expect(token.IF)
-		clause     = p.parseIfClause()
 		structExpr = p.parseStruct()
 		elif       *ast.Else
 	)
@@ -787,12 +972,67 @@ func (p *parser) parseListComprehensionBody() (expr *ast.ListComprehension) {
 
 	forPos := p.expect(token.FOR)
 	clause := p.parseForClause()
-	body := p.parseExpr()
+	clauses := p.parseClauses()
+
+	// an if clause that is followed by then, or by a struct and else, is the start of
+	// the value, as in [for x in xs if x.ok then 1 else 2]
+	var (
+		body     ast.Expr
+		ifClause *ast.IfClause
+	)
+	if len(clauses) > 0 {
+		ifClause, _ = clauses[len(clauses)-1].(*ast.IfClause)
+	}
+	if ifClause != nil && p.atThen() {
+		clauses = clauses[:len(clauses)-1]
+		body = p.parseConditional(ifClause.If, ifClause)
+	} else {
+		body = p.parseExpr()
+		if structLit, ok := body.(*ast.StructLit); ok && ifClause != nil && p.tok == token.ELSE {
+			clauses = clauses[:len(clauses)-1]
+			ifPos := ifClause.If
+			ifClause.If = token.NoPos
+			body = &ast.If{
+				If:        ifPos,
+				Condition: ifClause,
+				Struct:    structLit,
+				Else:      p.parseElse(),
+			}
+		}
+	}
 
 	return &ast.ListComprehension{
-		For:    forPos,
-		Clause: clause,
-		Value:  body,
+		For:     forPos,
+		Clause:  clause,
+		Clauses: clauses,
+		Value:   body,
+	}
+}
+
+// parseClauses parses the for, if and let clauses that follow the first for clause
+// of a comprehension, as in for a in xs for b in a.items if b.enabled let n: b.name
+func (p *parser) parseClauses() (clauses []ast.Clause) {
+	if p.trace {
+		defer un(trace(p, "Clauses"))
+	}
+
+	for {
+		switch p.tok {
+		case token.FOR:
+			forPos := p.expect(token.FOR)
+			clause := p.parseForClause()
+			clause.For = forPos
+			clauses = append(clauses, clause)
+		case token.IF:
+			ifPos := p.expect(token.IF)
+			clause := p.parseIfClause()
+			clause.If = ifPos
+			clauses = append(clauses, clause)
+		case token.LET:
+			clauses = append(clauses, p.parseLetDecl().(*ast.LetClause))
+		default:
+			return clauses
+		}
 	}
 }
 
@@ -806,6 +1046,7 @@ func (p *parser) parseFor() (expr *ast.For) {
 
 	forPos := p.expect(token.FOR)
 	clause := p.parseForClause()
+	clauses := p.parseClauses()
 	structExpr := p.parseStruct()
 	var elif *ast.Else
 
@@ -814,10 +1055,11 @@ func (p *parser) parseFor() (expr *ast.For) {
 	}
 
 	return &ast.For{
-		For:    forPos,
-		Clause: clause,
-		Struct: structExpr,
-		Else:   elif,
+		For:     forPos,
+		Clause:  clause,
+		Clauses: clauses,
+		Struct:  structExpr,
+		Else:    elif,
 	}
 }
 
@@ -874,9 +1116,26 @@ func (p *parser) parseDecl() (decl ast.Decl) {
 		}
 	}()
 
+	if p.tok == token.ELLIPSIS {
+		return p.parseSpread()
+	}
 	return p.parseDeclInline()
 }
 
+// parseSpread parses ...X, which is only valid as a declaration in a struct or an
+// element of a list
+func (p *parser) parseSpread() *ast.Spread {
+	if p.trace {
+		defer un(trace(p, "Spread"))
+	}
+
+	ellipsis := p.expect(token.ELLIPSIS)
+	return &ast.Spread{
+		Ellipsis: ellipsis,
+		X:        p.checkExpr(p.parseExpr()),
+	}
+}
+
 func (p *parser) parseDeclInline() (decl ast.Decl) {
 	if p.trace {
 		defer un(trace(p, "Decl"))
@@ -887,20 +1146,31 @@ func (p *parser) parseDeclInline() (decl ast.Decl) {
 		return p.parseLetDecl()
 	}
 
+	return p.parseFieldDecl(p.parseExpr())
+}
+
+// parseFieldDecl parses the rest of a field whose label is expr, or returns expr as an
+// embedded declaration if it is not followed by a colon
+func (p *parser) parseFieldDecl(expr ast.Expr) ast.Decl {
 	field := &ast.Field{}
-	expr := p.parseExpr()
 	if match, label, ok := p.checkAndParseValidLabel(expr); ok {
 		field.Label = label
 		field.Match = match
+	} else if bad, ok := expr.(*ast.BadExpr); ok {
+		return &ast.BadDecl{
+			From: bad.From,
+			To:   bad.To,
+			Text: bad.Text,
+		}
 	} else {
 		return &ast.EmbedDecl{
 			Expr: expr,
 		}
 	}
 
-	if p.tok == token.OPTION {
-		// If an option is found then it must be a field at this point
-		field.Constraint = token.OPTION
+	if p.tok == token.OPTION || p.tok == token.NOT {
+		// If an option or override is found then it must be a field at this point
+		field.Constraint = p.tok
 		p.next()
 	} else if p.tok != token.COLON {
 		// It's a valid label but no colon found, so it's an embedded decl
@@ -913,16 +1183,66 @@ func (p *parser) parseDeclInline() (decl ast.Decl) {
 	// consume
 	p.expect(token.COLON)
 
-	decl = p.parseDeclInline()
+	decl := p.parseDeclInline()
 	switch node := decl.(type) {
 	case *ast.EmbedDecl:
 		field.Value = node.Expr
+	case *ast.BadDecl:
+		field.Value = &ast.BadExpr{
+			From: node.From,
+			To:   node.To,
+			Text: node.Text,
+		}
 	default:
+		// attributes after the value belong to the nested field
 		field.Value = &ast.StructLit{
 			Elts: []ast.Decl{decl},
 		}
+		return field
+	}
+
+	p.parseAttributes(field)
+	return field
+}
+
+func (p *parser) parseAttributes(field *ast.Field) {
+	for p.tok == token.ATTRIBUTE {
+		field.Attrs = append(field.Attrs, &ast.Attribute{
+			At:   p.pos,
+			Text: p.lit,
+		})
+		p.next()
+	}
+}
+
+// parseDefineDecl parses the declaration of a define, which can extend other schemas as
+// in define Web extends Base: {...}
+func (p *parser) parseDefineDecl() ast.Decl {
+	if p.tok == token.LET {
+		return p.parseLetDecl()
 	}
 
+	expr := p.parseExpr()
+	label, ok := expr.(*ast.Ident)
+	if !ok || p.tok != token.IDENT || p.lit != "extends" {
+		return p.parseFieldDecl(expr)
+	}
+
+	field := &ast.Field{
+		Label:   label,
+		Extends: p.pos,
+	}
+	p.next()
+	for {
+		field.Bases = append(field.Bases, p.parseRHS())
+		if p.tok != token.COMMA {
+			break
+		}
+		p.next()
+	}
+	field.Colon = p.expect(token.COLON)
+	field.Value = p.parseRHS()
+	p.parseAttributes(field)
 	return field
 }
 
@@ -938,7 +1258,7 @@ func (p *parser) parseSchema() (expr *ast.SchemaLit) {
 	defer p.closeList()
 
 	schema := p.expect(token.SCHEMA)
-	decl := p.parseDeclInline()
+	decl := p.parseDefineDecl()
 
 	return &ast.SchemaLit{
 		Schema: schema,
@@ -983,29 +1303,28 @@ func (p *parser) parseForClause() (clause *ast.ForClause) {
 	c := p.openComments()
 	defer func() { c.closeNode(p, clause) }()
 
-	var (
-		key, value *ast.Ident
-		comma      token.Pos
-	)
+	clause = &ast.ForClause{}
 
-	value = p.parseIdent()
+	value := p.parsePattern()
 	if p.tok == token.COMMA {
-		comma = p.expect(token.COMMA)
-		key = value
-		value = p.parseIdent()
+		clause.Comma = p.expect(token.COMMA)
+		if key, ok := value.(*ast.Ident); ok {
+			clause.Key = key
+		} else {
+			p.errf(value.Pos(), "expected name for the key of a for clause, only the value can be destructured")
+		}
+		value = p.parsePattern()
+	}
+	if ident, ok := value.(*ast.Ident); ok {
+		clause.Value = ident
+	} else {
+		clause.Pattern = value
 	}
 	c.pos = 4
 
-	inTok := p.expect(token.IN)
-	source := p.parseRHS()
-
-	return &ast.ForClause{
-		Key:    key,
-		Comma:  comma,
-		Value:  value,
-		In:     inTok,
-		Source: source,
-	}
+	clause.In = p.expect(token.IN)
+	clause.Source = p.parseRHS()
+	return clause
 }
 
 func (p *parser) parseIfClause() (clause *ast.IfClause) {
@@ -1120,7 +1439,11 @@ func (p *parser) parseListElement() (expr ast.Expr, ok bool) {
 	c := p.openComments()
 	defer func() { c.closeNode(p, expr) }()
 
-	expr = p.parseExpr()
+	if p.tok == token.ELLIPSIS {
+		expr = p.parseSpread()
+	} else {
+		expr = p.parseExpr()
+	}
 
 	// Enforce there is an explicit comma. We could also allow the
 	// omission of commas in lists, but this gives rise to some ambiguities
@@ -1164,11 +1487,13 @@ func (p *parser) checkExpr(x ast.Expr) ast.Expr {
 	case *ast.ListComprehension:
 	case *ast.SchemaLit:
 	case *ast.DefaultExpr:
+	case *ast.ConditionalExpr:
 	default:
 		// all other nodes are not proper expressions
 		p.errorExpected(x.Pos(), "expression")
 		x = &ast.BadExpr{
 			From: x.Pos(), To: p.safePos(x.End()),
+			Text: p.sourceText(x.Pos(), x.End()),
 		}
 	}
 	return x
@@ -1196,24 +1521,26 @@ func (p *parser) parsePrimaryExprTail(operand ast.Expr) ast.Expr {
 L:
 	for {
 		switch p.tok {
-		case token.PERIOD:
+		case token.PERIOD, token.OPTPERIOD:
 			c := p.openComments()
 			c.pos = 1
+			optional := p.tok == token.OPTPERIOD
 			p.next()
 			switch p.tok {
 			case token.IDENT:
 				x = &ast.SelectorExpr{
-					X:   p.checkExpr(x),
-					Sel: p.parseIdent(),
+					X:        p.checkExpr(x),
+					Sel:      p.parseIdent(),
+					Optional: optional,
 				}
 			default:
 				pos := p.pos
 				p.errorExpected(pos, "selector")
 				p.next() // make progress
-				x = &ast.SelectorExpr{X: x, Sel: &ast.Ident{NamePos: pos, Name: "_"}}
+				x = &ast.BadExpr{From: x.Pos(), To: pos, Text: p.sourceText(x.Pos(), pos)}
 			}
 			c.closeNode(p, x)
-		case token.LBRACK:
+		case token.LBRACK, token.OPTLBRACK:
 			x = p.parseIndexOrSlice(p.checkExpr(x))
 		case token.LPAREN:
 			x = p.parseCall(p.checkExpr(x))
@@ -1249,6 +1576,11 @@ func (p *parser) parseUnaryExpr() ast.Expr {
 func (p *parser) tokPrec() (token.Token, int) {
 	tok := p.tok
 	if tok == token.IDENT {
+		// div is only an operator between two operands so that it can still be used
+		// as an identifier, as in std.math.div
+		if p.lit == token.IDIV.String() {
+			return token.IDIV, token.IDIV.Precedence()
+		}
 		return tok, 0
 	}
 	return tok, tok.Precedence()
@@ -1274,12 +1606,16 @@ func (p *parser) parseBinaryExprTail(prec1 int, x ast.Expr) ast.Expr {
 		c := p.openComments()
 		c.pos = 1
 		pos := p.expect(p.tok)
+		yPrec := prec + 1
+		if op.RightAssociative() {
+			yPrec = prec
+		}
 		x = c.closeExpr(p, &ast.BinaryExpr{
 			X:     p.checkExpr(x),
 			OpPos: pos,
 			Op:    op,
 			// Treat nested expressions as RHS.
-			Y: p.checkExpr(p.parseBinaryExpr(prec + 1))})
+			Y: p.checkExpr(p.parseBinaryExpr(yPrec))})
 	}
 }
 
@@ -1354,11 +1690,22 @@ func (p *parser) parseFile() *ast.File {
 		return nil
 	}
 
-	decls := p.parseFieldList()
+	p.parseFieldListInto(&p.decls)
+	for p.mode&tolerantMode != 0 && p.tok == token.RBRACE {
+		// skip the unmatched brace so the declarations after it are kept
+		from := p.pos
+		p.errf(from, "unexpected '}'")
+		p.next()
+		if p.tok == token.COMMA {
+			p.next()
+		}
+		p.decls = append(p.decls, &ast.BadDecl{From: from, To: p.pos, Text: p.sourceText(from, p.pos)})
+		p.parseFieldListInto(&p.decls)
+	}
 	p.expect(token.EOF)
 
 	f := &ast.File{
-		Decls: decls,
+		Decls: p.decls,
 	}
 
 	c.closeNode(p, f)
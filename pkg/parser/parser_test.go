@@ -34,3 +34,27 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTolerant(t *testing.T) {
+	dir := fmt.Sprintf("testdata/%s", t.Name())
+	files, err := os.ReadDir(dir)
+	require.Nil(t, err)
+
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".acorn") {
+			continue
+		}
+		t.Run(strings.TrimSuffix(file.Name(), ".acorn"), func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join(dir, file.Name()))
+			require.NoError(t, err)
+
+			ast, err := ParseFile(file.Name(), bytes.NewReader(data), Tolerant)
+			require.Error(t, err)
+			require.NotNil(t, ast)
+			autogold.ExpectFile(t, map[string]any{
+				"ast":    ast,
+				"errors": err.Error(),
+			})
+		})
+	}
+}
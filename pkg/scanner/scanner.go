@@ -22,6 +22,8 @@ import (
 	"fmt"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 	"unicode"
 	"unicode/utf8"
 
@@ -98,6 +100,15 @@ func (s *Scanner) next() {
 	}
 }
 
+// peekByte returns the byte n bytes after the current character without
+// advancing the scanner, or 0 past the end of the source.
+func (s *Scanner) peekByte(n int) byte {
+	if i := s.rdOffset + n - 1; i < len(s.src) {
+		return s.src[i]
+	}
+	return 0
+}
+
 const bom = 0xFEFF // byte order mark, only permitted as very first character
 
 // A Mode value is a set of flags (or 0).
@@ -179,7 +190,7 @@ func (s *Scanner) interpretLineComment(text []byte) {
 }
 
 func (s *Scanner) scanComment() string {
-	// initial '/' already consumed; s.ch == '/'
+	// initial '/' already consumed; s.ch == '/' || s.ch == '*'
 	offs := s.offset - 1 // position of initial '/'
 	hasCR := false
 
@@ -199,6 +210,20 @@ func (s *Scanner) scanComment() string {
 		goto exit
 	}
 
+	/*-style comment */
+	s.next()
+	for s.ch >= 0 {
+		ch := s.ch
+		if ch == '\r' {
+			hasCR = true
+		}
+		s.next()
+		if ch == '*' && s.ch == '/' {
+			s.next()
+			goto exit
+		}
+	}
+
 	s.errf(offs, "comment not terminated")
 
 exit:
@@ -211,6 +236,55 @@ exit:
 	return string(lit)
 }
 
+// findLineEnd reports whether the comments starting at the current position are
+// followed by the end of the line, in which case a comma should be inserted before
+// them.
+func (s *Scanner) findLineEnd() bool {
+	// initial '/' already consumed
+
+	defer func(offs int) {
+		// reset scanner state to where it was upon calling findLineEnd
+		s.ch = '/'
+		s.offset = offs
+		s.rdOffset = offs + 1
+		s.next() // consume initial '/' again
+	}(s.offset - 1)
+
+	// read ahead until a newline, EOF, or non-comment token is found
+	for s.ch == '/' || s.ch == '*' {
+		if s.ch == '/' {
+			//-style comment always contains a newline
+			return true
+		}
+		/*-style comment: look for newline */
+		s.next()
+		for s.ch >= 0 {
+			ch := s.ch
+			if ch == '\n' {
+				return true
+			}
+			s.next()
+			if ch == '*' && s.ch == '/' {
+				s.next()
+				break
+			}
+		}
+		for s.ch == ' ' || s.ch == '\t' || s.ch == '\r' {
+			s.next()
+		}
+		if s.ch < 0 || s.ch == '\n' {
+			return true
+		}
+		if s.ch != '/' {
+			// non-comment token
+			return false
+		}
+		s.next() // consume '/'
+	}
+
+	return false
+}
+
 func isLetter(ch rune) bool {
 	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch >= utf8.RuneSelf && unicode.IsLetter(ch)
 }
@@ -288,7 +362,7 @@ func (s *Scanner) scanNumber(seenDecimalPoint bool) (token.Token, string) {
 			// integer other than 0 may not start with 0
 			s.errf(offs, "illegal integer number")
 		}
-		goto exit
+		goto exponent
 	}
 
 	// decimal int or float
@@ -306,12 +380,30 @@ fraction:
 
 exponent:
 	switch s.ch {
-	case 'K', 'k', 'M', 'm', 'G', 'g', 'T', 't', 'P', 'p':
+	case 'K', 'k', 'M', 'G', 'g', 'T', 't', 'P', 'p':
 		s.next()
 		if s.ch == 'i' {
 			s.next()
 		}
 		goto exit
+	case 'm':
+		switch next := s.peekByte(1); {
+		case next == 'i' && s.peekByte(2) != 'n':
+			s.next()
+			s.next()
+			goto exit
+		case next == 's', next == 'i', '0' <= next && next <= '9':
+			// ms, min or the minutes of a compound duration such as 30m15s
+		default:
+			// a bare m is milli, as in the Kubernetes CPU quantity 500m
+			s.next()
+			goto exit
+		}
+	}
+
+	if s.scanDurationUnit() {
+		s.scanDuration(offs)
+		goto exit
 	}
 
 	if s.ch == 'e' || s.ch == 'E' {
@@ -326,6 +418,53 @@ exit:
 	return tok, string(s.src[offs:s.offset])
 }
 
+// scanDurationUnit consumes a duration unit (h, m, min, s, ms, us or ns) if one
+// is present at the current position.
+func (s *Scanner) scanDurationUnit() bool {
+	switch s.ch {
+	case 'h', 's':
+		s.next()
+		return true
+	case 'm':
+		s.next()
+		if s.ch == 's' {
+			s.next()
+		} else if s.ch == 'i' && s.peekByte(1) == 'n' {
+			s.next()
+			s.next()
+		}
+		return true
+	case 'u', 'n':
+		if s.peekByte(1) == 's' {
+			s.next()
+			s.next()
+			return true
+		}
+	}
+	return false
+}
+
+// scanDuration consumes the remaining components of a duration literal such
+// as 1h30m, the first component having already been scanned.
+func (s *Scanner) scanDuration(offs int) {
+	for '0' <= s.ch && s.ch <= '9' {
+		s.scanMantissa(10)
+		if s.ch == '.' {
+			s.next()
+			s.scanMantissa(10)
+		}
+		if !s.scanDurationUnit() {
+			s.errf(offs, "illegal duration, missing unit")
+			return
+		}
+	}
+
+	lit := strings.ReplaceAll(string(s.src[offs:s.offset]), "_", "")
+	if _, err := time.ParseDuration(strings.ReplaceAll(lit, "min", "m")); err != nil {
+		s.errf(offs, "illegal duration, out of range")
+	}
+}
+
 func (s *Scanner) scanString(offs int, quote quoteInfo) (token.Token, string) {
 	// ", """, `, or ``` opening already consumed
 
@@ -395,6 +534,56 @@ func stripCR(b []byte) []byte {
 	return c[:i]
 }
 
+// scanAttribute scans an attribute such as @sensitive or @k8s(name="img"). The @ is
+// already consumed.
+func (s *Scanner) scanAttribute() (token.Token, string) {
+	offs := s.offset - 1
+
+	if !isLetter(s.ch) {
+		s.errf(s.offset, "invalid attribute: expected name")
+		return token.ILLEGAL, string(s.src[offs:s.offset])
+	}
+	s.scanFieldIdentifier()
+
+	if s.ch == '(' {
+		s.next()
+		s.scanAttributeArgs()
+	}
+	return token.ATTRIBUTE, string(s.src[offs:s.offset])
+}
+
+// scanAttributeArgs scans the arguments of an attribute up to and including the
+// closing parenthesis. The arguments must be on one line and are only checked for
+// balanced brackets and terminated strings.
+func (s *Scanner) scanAttributeArgs() {
+	offs := s.offset
+	depth := 1
+	for depth > 0 {
+		switch s.ch {
+		case '\n', -1:
+			s.errf(offs, "attribute missing ')'")
+			return
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case '"':
+			s.next()
+			for s.ch != '"' {
+				if s.ch == '\n' || s.ch < 0 {
+					s.errf(offs, "string literal in attribute not terminated")
+					return
+				}
+				if s.ch == '\\' {
+					s.next()
+				}
+				s.next()
+			}
+		}
+		s.next()
+	}
+}
+
 // recoverParen is an approximate recovery mechanism to recover from invalid
 // attributes.
 func (s *Scanner) recoverParen(open int) {
@@ -564,13 +753,32 @@ scanAgain:
 			}
 		case ':':
 			tok = token.COLON
-		case '?':
-			tok = token.OPTION
+		case '@':
 			insertEOL = true
+			tok, lit = s.scanAttribute()
+		case '?':
+			switch s.ch {
+			case '?':
+				s.next()
+				tok = token.COALESCE
+			case '.':
+				s.next()
+				tok = token.OPTPERIOD
+			case '[':
+				s.next()
+				tok = token.OPTLBRACK
+			default:
+				tok = token.OPTION
+				insertEOL = true
+			}
 		case '.':
 			if '0' <= s.ch && s.ch <= '9' {
 				insertEOL = true
 				tok, lit = s.scanNumber(true)
+			} else if s.ch == '.' && s.peekByte(1) == '.' {
+				s.next()
+				s.next()
+				tok = token.ELLIPSIS
 			} else {
 				tok = token.PERIOD
 			}
@@ -598,10 +806,14 @@ scanAgain:
 			tok = token.SUB
 		case '*':
 			tok = token.MUL
+			if s.ch == '*' {
+				s.next()
+				tok = token.POW
+			}
 		case '/':
-			if s.ch == '/' {
+			if s.ch == '/' || s.ch == '*' {
 				// comment
-				if s.insertEOL {
+				if s.insertEOL && s.findLineEnd() {
 					// reset position to the beginning of the comment
 					s.ch = '/'
 					s.offset = s.file.Offset(pos)
@@ -622,7 +834,8 @@ scanAgain:
 			}
 		// We no longer use %, but seems like a useful token to use for
 		// something else at some point.
-		// case '%':
+		case '%':
+			tok = token.REM
 		case '<':
 			tok = s.switch2(token.LSS, token.LEQ)
 		case '>':
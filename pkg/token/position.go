@@ -174,6 +174,15 @@ func (p Pos) IsValid() bool {
 	return p != NoPos
 }
 
+// IsInFile reports whether the position is valid and within the range of its file.
+func (p Pos) IsInFile() bool {
+	if p.file == nil {
+		return false
+	}
+	x := p.index()
+	return x >= p.file.base && x <= p.file.base+p.file.size
+}
+
 // IsNewline reports whether the relative information suggests this node should
 // be printed on a new lien.
 func (p Pos) IsNewline() bool {
@@ -37,19 +37,25 @@ const (
 	NUMBER        // any numner int or float
 	STRING        // "abc"
 	INTERPOLATION // a part of a template string, e.g. `"age: \(`
+	ATTRIBUTE     // @foo(bar)
 
 	literalEnd
 
 	operatorBeg
 	// Operators and delimiters
-	ADD // +
-	SUB // -
-	MUL // *
-	QUO // /
+	ADD  // +
+	SUB  // -
+	MUL  // *
+	QUO  // /
+	REM  // %
+	IDIV // div
+	POW  // **
 
 	LAND // &&
 	LOR  // ||
 
+	COALESCE // ??
+
 	EQL // ==
 	LSS // <
 	GTR // >
@@ -73,6 +79,10 @@ const (
 	RBRACE // }
 	COLON  // :
 	OPTION // ?
+
+	OPTPERIOD // ?.
+	OPTLBRACK // ?[
+	ELLIPSIS  // ...
 	operatorEnd
 
 	keywordBeg
@@ -104,15 +114,21 @@ var tokens = [...]string{
 	NUMBER:        "NUMBER",
 	STRING:        "STRING",
 	INTERPOLATION: "INTERPOLATION",
+	ATTRIBUTE:     "ATTRIBUTE",
 
-	ADD: "+",
-	SUB: "-",
-	MUL: "*",
-	QUO: "/",
+	ADD:  "+",
+	SUB:  "-",
+	MUL:  "*",
+	QUO:  "/",
+	REM:  "%",
+	IDIV: "div",
+	POW:  "**",
 
 	LAND: "&&",
 	LOR:  "||",
 
+	COALESCE: "??",
+
 	EQL: "==",
 	LSS: "<",
 	GTR: ">",
@@ -137,6 +153,10 @@ var tokens = [...]string{
 	COLON:  ":",
 	OPTION: "?",
 
+	OPTPERIOD: "?.",
+	OPTLBRACK: "?[",
+	ELLIPSIS:  "...",
+
 	FALSE: "false",
 	TRUE:  "true",
 	NULL:  "null",
@@ -181,8 +201,8 @@ const (
 
 const (
 	lowestPrec  = 0 // non-operators
-	unaryPrec   = 8
-	highestPrec = 9
+	unaryPrec   = 9
+	highestPrec = 10
 )
 
 // Precedence returns the operator precedence of the binary
@@ -190,20 +210,30 @@ const (
 // is LowestPrecedence.
 func (tok Token) Precedence() int {
 	switch tok {
+	case COALESCE:
+		return 2
 	case LOR:
 		return 3
 	case LAND:
 		return 4
-	case EQL, NEQ, LSS, LEQ, GTR, GEQ, MAT, NMAT:
+	case EQL, NEQ, LSS, LEQ, GTR, GEQ, MAT, NMAT, IN:
 		return 5
 	case ADD, SUB:
 		return 6
-	case MUL, QUO:
+	case MUL, QUO, REM, IDIV:
 		return 7
+	case POW:
+		return 8
 	}
 	return lowestPrec
 }
 
+// RightAssociative returns true if a sequence of the binary operator op groups from
+// the right, as in 2 ** 3 ** 2 == 2 ** (3 ** 2)
+func (tok Token) RightAssociative() bool {
+	return tok == POW
+}
+
 var keywords map[string]Token
 
 func init() {
@@ -0,0 +1,125 @@
+package value
+
+import (
+	"fmt"
+	"strings"
+)
+
+const mergeByKeyPrefix = "mergeByKey:"
+
+// ArrayMerge is the strategy to merge two arrays found at the same key of merged
+// objects. The zero value replaces the left array with the right one.
+type ArrayMerge struct {
+	// Append adds the items of the right array after the items of the left array
+	Append bool `json:"append,omitempty"`
+	// Key merges the items that are objects with the same value for Key, and adds the
+	// other items of the right array after the items of the left array
+	Key string `json:"key,omitempty"`
+}
+
+// ParseArrayMerge parses a strategy written as replace, append or mergeByKey:<key>
+func ParseArrayMerge(s string) (ArrayMerge, error) {
+	switch {
+	case s == "replace":
+		return ArrayMerge{}, nil
+	case s == "append":
+		return ArrayMerge{Append: true}, nil
+	case strings.HasPrefix(s, mergeByKeyPrefix) && len(s) > len(mergeByKeyPrefix):
+		return ArrayMerge{Key: strings.TrimPrefix(s, mergeByKeyPrefix)}, nil
+	}
+	return ArrayMerge{}, fmt.Errorf("invalid array merge strategy %q, expected replace, append or %s<key>", s, mergeByKeyPrefix)
+}
+
+func (a ArrayMerge) String() string {
+	switch {
+	case a.Key != "":
+		return mergeByKeyPrefix + a.Key
+	case a.Append:
+		return "append"
+	}
+	return "replace"
+}
+
+// Merge merges the arrays left and right. Items with the same key are merged with
+// mergeItems.
+func (a ArrayMerge) Merge(left, right Value, mergeItems func(left, right Value) (Value, error)) (Value, error) {
+	if a.Key == "" && !a.Append {
+		return right, nil
+	}
+
+	leftItems, err := ToValueArray(left)
+	if err != nil {
+		return nil, err
+	}
+	rightItems, err := ToValueArray(right)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Value, 0, len(leftItems)+len(rightItems))
+	result = append(result, leftItems...)
+	if a.Append {
+		return Array(append(result, rightItems...)), nil
+	}
+
+	leftKeys := make([]Value, 0, len(leftItems))
+	for _, item := range leftItems {
+		key, err := a.itemKey(item)
+		if err != nil {
+			return nil, err
+		}
+		leftKeys = append(leftKeys, key)
+	}
+
+	for _, item := range rightItems {
+		key, err := a.itemKey(item)
+		if err != nil {
+			return nil, err
+		}
+		i, err := indexOfKey(leftKeys, key)
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			result = append(result, item)
+			continue
+		}
+		result[i], err = mergeItems(result[i], item)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return Array(result), nil
+}
+
+func (a ArrayMerge) itemKey(item Value) (Value, error) {
+	if !IsObjectLike(item) {
+		return nil, fmt.Errorf("can not merge array by key %s, found item of kind %s, expected an object", a.Key, item.Kind())
+	}
+	key, ok, err := Lookup(item, NewValue(a.Key))
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, fmt.Errorf("can not merge array by key %s, found item %s without the key", a.Key, item)
+	}
+	return key, nil
+}
+
+func indexOfKey(keys []Value, key Value) (int, error) {
+	for i, existing := range keys {
+		if existing.Kind() != key.Kind() {
+			continue
+		}
+		b, err := Eq(existing, key)
+		if err != nil {
+			return 0, err
+		}
+		if b, err := ToBool(b); err != nil {
+			return 0, err
+		} else if b {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
@@ -0,0 +1,46 @@
+package value
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseArrayMerge(t *testing.T) {
+	for _, s := range []string{"replace", "append", "mergeByKey:name"} {
+		arrays, err := ParseArrayMerge(s)
+		require.NoError(t, err)
+		assert.Equal(t, s, arrays.String())
+	}
+
+	_, err := ParseArrayMerge("mergeByKey:")
+	assert.EqualError(t, err, `invalid array merge strategy "mergeByKey:", expected replace, append or mergeByKey:<key>`)
+}
+
+func TestArrayMergeByKey(t *testing.T) {
+	left := NewValue([]any{
+		map[string]any{"name": "a", "port": 1},
+		map[string]any{"name": "b", "port": 2},
+	})
+	right := NewValue([]any{
+		map[string]any{"name": "b", "port": 3},
+		map[string]any{"name": "c", "port": 4},
+	})
+
+	merged, err := ArrayMerge{Key: "name"}.Merge(left, right, func(_, right Value) (Value, error) {
+		return right, nil
+	})
+	require.NoError(t, err)
+
+	nv, _, err := NativeValue(merged)
+	require.NoError(t, err)
+	assert.Equal(t, []any{
+		map[string]any{"name": "a", "port": Number("1")},
+		map[string]any{"name": "b", "port": Number("3")},
+		map[string]any{"name": "c", "port": Number("4")},
+	}, nv)
+
+	_, err = ArrayMerge{Key: "id"}.Merge(left, right, nil)
+	assert.ErrorContains(t, err, "can not merge array by key id")
+}
@@ -11,6 +11,8 @@ type ArraySchema struct {
 	Positions   []Position `json:"-"`
 	Description string     `json:"description"`
 	Valid       []Schema   `json:"valid"`
+	// Unique requires that no two items of the array are equal
+	Unique bool `json:"unique,omitempty"`
 }
 
 func (n *ArraySchema) ImpliedDefault() (Value, bool, error) {
@@ -29,12 +31,27 @@ func (a *ArraySchema) Validate(ctx context.Context, right Value) (Value, error)
 		return nil, err
 	}
 
+	// items that have the tag of a discriminated set of object schemas are only checked
+	// against the schema with that tag
+	d, discriminated := newDiscriminator(a.Valid, (*TypeSchema).resolve)
+
 valueLoop:
 	for i, value := range values {
 		var (
 			errs []error
 			ctx  = WithDataIndexPath(ctx, i)
 		)
+		if discriminated {
+			if valid, ok, err := d.validate(ctx, value, nil); err != nil {
+				return nil, err
+			} else if ok {
+				resultValues = append(resultValues, valid)
+				continue
+			}
+		}
+		if len(a.Valid) > 1 {
+			ctx = alternateContext(ctx)
+		}
 		for _, validater := range a.Valid {
 			if valid, err := validater.Validate(ctx, value); err == nil {
 				resultValues = append(resultValues, valid)
@@ -50,9 +67,37 @@ valueLoop:
 			resultValues = append(resultValues, value)
 		}
 	}
+
+	if a.Unique {
+		if err := checkUnique(resultValues); err != nil {
+			return nil, err
+		}
+	}
+
 	return NewValue(resultValues), nil
 }
 
+// checkUnique returns an error if two of the values are equal
+func checkUnique(values []Value) error {
+	for i, value := range values {
+		for j := 0; j < i; j++ {
+			if values[j].Kind() != value.Kind() {
+				continue
+			}
+			eq, err := Eq(values[j], value)
+			if err != nil {
+				return err
+			}
+			if b, err := ToBool(eq); err != nil {
+				return err
+			} else if b {
+				return fmt.Errorf("array items must be unique, item %d is the same as item %d", i, j)
+			}
+		}
+	}
+	return nil
+}
+
 func (a *ArraySchema) Merge(right *ArraySchema) (*ArraySchema, error) {
 	if a == nil {
 		return right, nil
@@ -70,6 +115,7 @@ func (a *ArraySchema) Merge(right *ArraySchema) (*ArraySchema, error) {
 	result := &ArraySchema{
 		Positions:   mergePositions(a.Positions, right.Positions),
 		Description: mergeDescription(a.Description, right.Description),
+		Unique:      a.Unique || right.Unique,
 	}
 
 	for i, leftType := range a.Valid {
@@ -0,0 +1,132 @@
+package value
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Attribute is an annotation of a field such as @sensitive or @k8s(name="img")
+type Attribute struct {
+	Name string         `json:"name"`
+	Args []AttributeArg `json:"args,omitempty"`
+}
+
+// AttributeArg is an argument of an attribute. Key is empty for positional arguments.
+type AttributeArg struct {
+	Key   string `json:"key,omitempty"`
+	Value string `json:"value"`
+}
+
+// Lookup returns the value of the argument with the given key
+func (a Attribute) Lookup(key string) (string, bool) {
+	for _, arg := range a.Args {
+		if arg.Key == key {
+			return arg.Value, true
+		}
+	}
+	return "", false
+}
+
+// Attributes are the attributes of a field in the order they were written
+type Attributes []Attribute
+
+// Get returns the attribute with the given name
+func (a Attributes) Get(name string) (Attribute, bool) {
+	for _, attr := range a {
+		if attr.Name == name {
+			return attr, true
+		}
+	}
+	return Attribute{}, false
+}
+
+// Has returns true if an attribute with the given name is set
+func (a Attributes) Has(name string) bool {
+	_, ok := a.Get(name)
+	return ok
+}
+
+// Merge returns the attributes of a and right. Attributes of right replace the
+// attributes of a with the same name.
+func (a Attributes) Merge(right Attributes) Attributes {
+	result := slices.Clone(a)
+	for _, attr := range right {
+		i := slices.IndexFunc(result, func(existing Attribute) bool {
+			return existing.Name == attr.Name
+		})
+		if i < 0 {
+			result = append(result, attr)
+		} else {
+			result[i] = attr
+		}
+	}
+	return result
+}
+
+// ParseAttribute parses the arguments of an attribute, which are the text between the
+// parentheses of @name(args). Arguments are separated by commas and are either a value
+// or key=value. Values can be quoted strings.
+func ParseAttribute(name, args string) (Attribute, error) {
+	result := Attribute{
+		Name: name,
+	}
+	if strings.TrimSpace(args) == "" {
+		return result, nil
+	}
+
+	for _, arg := range splitAttributeArgs(args) {
+		var (
+			key string
+			val = strings.TrimSpace(arg)
+		)
+		if i := strings.IndexByte(val, '='); i >= 0 && !strings.ContainsAny(val[:i], `"([{`) {
+			key, val = strings.TrimSpace(val[:i]), strings.TrimSpace(val[i+1:])
+			if key == "" {
+				return result, fmt.Errorf("invalid attribute @%s: missing key before =", name)
+			}
+		}
+		if val == "" && key == "" {
+			return result, fmt.Errorf("invalid attribute @%s: empty argument", name)
+		}
+		if strings.HasPrefix(val, `"`) {
+			s, err := Unquote(val)
+			if err != nil {
+				return result, fmt.Errorf("invalid attribute @%s: %w", name, err)
+			}
+			val = s
+		}
+		result.Args = append(result.Args, AttributeArg{
+			Key:   key,
+			Value: val,
+		})
+	}
+
+	return result, nil
+}
+
+// splitAttributeArgs splits args at the commas that are not in a string or brackets
+func splitAttributeArgs(args string) (result []string) {
+	var (
+		depth   int
+		inQuote bool
+		start   int
+	)
+	for i := 0; i < len(args); i++ {
+		switch c := args[i]; {
+		case inQuote && c == '\\':
+			i++
+		case c == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			result = append(result, args[start:i])
+			start = i + 1
+		}
+	}
+	return append(result, args[start:])
+}
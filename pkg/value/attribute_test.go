@@ -0,0 +1,53 @@
+package value
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAttribute(t *testing.T) {
+	attr, err := ParseAttribute("k8s", ` name="img, latest", main , ports=[1, 2] `)
+	require.NoError(t, err)
+	assert.Equal(t, Attribute{
+		Name: "k8s",
+		Args: []AttributeArg{
+			{Key: "name", Value: "img, latest"},
+			{Value: "main"},
+			{Key: "ports", Value: "[1, 2]"},
+		},
+	}, attr)
+
+	name, ok := attr.Lookup("name")
+	assert.True(t, ok)
+	assert.Equal(t, "img, latest", name)
+
+	attr, err = ParseAttribute("sensitive", "")
+	require.NoError(t, err)
+	assert.Equal(t, Attribute{Name: "sensitive"}, attr)
+
+	_, err = ParseAttribute("bad", "a,,b")
+	assert.EqualError(t, err, "invalid attribute @bad: empty argument")
+
+	_, err = ParseAttribute("bad", "=b")
+	assert.EqualError(t, err, "invalid attribute @bad: missing key before =")
+}
+
+func TestAttributesMerge(t *testing.T) {
+	attrs := Attributes{
+		{Name: "sensitive"},
+		{Name: "ui", Args: []AttributeArg{{Value: "text"}}},
+	}.Merge(Attributes{
+		{Name: "ui", Args: []AttributeArg{{Value: "slider"}}},
+		{Name: "k8s"},
+	})
+
+	assert.Equal(t, Attributes{
+		{Name: "sensitive"},
+		{Name: "ui", Args: []AttributeArg{{Value: "slider"}}},
+		{Name: "k8s"},
+	}, attrs)
+	assert.True(t, attrs.Has("k8s"))
+	assert.False(t, attrs.Has("missing"))
+}
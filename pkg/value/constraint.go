@@ -61,8 +61,33 @@ func (c *CustomConstraint) RightNative() (any, bool, error) {
 }
 
 type Constraint struct {
-	Op    string `json:"op,omitempty"`
-	Right Value  `json:"right,omitempty"`
+	Op    string      `json:"op,omitempty"`
+	Right Value       `json:"right,omitempty"`
+	Apply []Operation `json:"apply,omitempty"`
+}
+
+// Operation is an arithmetic operation that is applied to the value before it is
+// checked against a constraint, such as the % 2 in int % 2 == 0
+type Operation struct {
+	Op    Operator `json:"op"`
+	Right Value    `json:"right"`
+}
+
+func (o Operation) apply(left Value) (Value, error) {
+	if o.Op == LenOp {
+		return Len(left)
+	}
+	return BinaryOperation(o.Op, left, func() (Value, error) {
+		return o.Right, nil
+	})
+}
+
+// format returns the text of the operation applied to left, as in value % 2 or len(value)
+func (o Operation) format(left string) string {
+	if o.Op == LenOp {
+		return fmt.Sprintf("len(%s)", left)
+	}
+	return fmt.Sprintf("%s %s %s", left, o.Op, o.Right)
 }
 
 func toConcrete(val Value) (Value, error) {
@@ -81,6 +106,14 @@ func (c *Constraint) check(op Operator, left, right Value) error {
 	if err != nil {
 		return err
 	}
+	operations := "value"
+	for _, operation := range c.Apply {
+		left, err = operation.apply(left)
+		if err != nil {
+			return err
+		}
+		operations = operation.format(operations)
+	}
 	v, err := BinaryOperation(op, left, func() (Value, error) {
 		return toConcrete(right)
 	})
@@ -92,7 +125,7 @@ func (c *Constraint) check(op Operator, left, right Value) error {
 		return err
 	}
 	if !b {
-		return fmt.Errorf("constraint [value %s %s] is not true", c.Op, right)
+		return fmt.Errorf("constraint [%s %s %s] is not true", operations, c.Op, right)
 	}
 	return nil
 }
@@ -1,6 +1,7 @@
 package value
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/acorn-io/aml/pkg/jsonschema"
@@ -50,9 +51,13 @@ func toSchema(defs map[string]jsonschema.Schema, summary *Summary, amlSchema *Ty
 		if ok {
 			return &schema, nil
 		}
+		// a placeholder ends the conversion of schemas that refer to themselves, which
+		// become a $ref to the definition
+		defs[amlSchema.Path.String()] = jsonschema.Schema{}
 		target := summary.Types[amlSchema.Path.String()]
 		newSchema, err := toSchema(defs, summary, target.(*TypeSchema))
 		if err != nil || newSchema == nil {
+			delete(defs, amlSchema.Path.String())
 			return newSchema, err
 		}
 
@@ -60,13 +65,19 @@ func toSchema(defs map[string]jsonschema.Schema, summary *Summary, amlSchema *Ty
 		return newSchema, nil
 	}
 
+	if d, ok := amlSchema.discriminator(summary.resolve); ok {
+		return toOneOf(defs, summary, d)
+	}
+
 	switch amlSchema.KindValue {
 	case StringKind:
-		return &jsonschema.Schema{
+		str := &jsonschema.Schema{
 			Property: jsonschema.Property{
 				Type: "string",
 			},
-		}, nil
+		}
+		str.MinLength, str.MaxLength = lengthBounds(amlSchema)
+		return str, nil
 	case BoolKind:
 		return &jsonschema.Schema{
 			Property: jsonschema.Property{
@@ -85,6 +96,7 @@ func toSchema(defs map[string]jsonschema.Schema, summary *Summary, amlSchema *Ty
 				Type: "array",
 			},
 		}
+		array.MinItems, array.MaxItems = lengthBounds(amlSchema)
 		if amlSchema.Array == nil {
 			return array, nil
 		}
@@ -101,6 +113,7 @@ func toSchema(defs map[string]jsonschema.Schema, summary *Summary, amlSchema *Ty
 		}
 		array.Description = amlSchema.Array.Description
 		array.Items = items
+		array.UniqueItems = amlSchema.Array.Unique
 		return array, nil
 	case ObjectKind:
 		obj := &jsonschema.Schema{
@@ -109,6 +122,7 @@ func toSchema(defs map[string]jsonschema.Schema, summary *Summary, amlSchema *Ty
 			},
 			Properties: map[string]jsonschema.Property{},
 		}
+		obj.MinProperties, obj.MaxProperties = lengthBounds(amlSchema)
 		if amlSchema.Object == nil {
 			return obj, nil
 		}
@@ -123,6 +137,7 @@ func toSchema(defs map[string]jsonschema.Schema, summary *Summary, amlSchema *Ty
 					return nil, err
 				}
 				property.Description = prop.Description
+				setDocTags(&property.Property, prop.DocTags)
 				obj.Properties[prop.Key] = property.Property
 			}
 		}
@@ -133,3 +148,114 @@ func toSchema(defs map[string]jsonschema.Schema, summary *Summary, amlSchema *Ty
 
 	return nil, nil
 }
+
+// toOneOf converts a discriminated union to oneOf the alternates with a discriminator that
+// maps the tags to the definitions of the alternates
+func toOneOf(defs map[string]jsonschema.Schema, summary *Summary, d *Discriminator) (*jsonschema.Schema, error) {
+	result := &jsonschema.Schema{
+		Property: jsonschema.Property{
+			Discriminator: &jsonschema.Discriminator{
+				PropertyName: d.Key,
+			},
+		},
+	}
+
+	paths := map[string]int{}
+	for _, alt := range d.Alternates {
+		paths[alt.Path.String()]++
+	}
+
+	for i, alt := range d.Alternates {
+		// alternates that do not have their own path are included inline
+		convert := toRef
+		if path := alt.Path.String(); path == "" || paths[path] > 1 {
+			convert = toSchema
+		}
+		schema, err := convert(defs, summary, alt)
+		if err != nil {
+			return nil, err
+		} else if schema == nil {
+			continue
+		}
+		if schema.Ref != "" {
+			if result.Discriminator.Mapping == nil {
+				result.Discriminator.Mapping = map[string]string{}
+			}
+			result.Discriminator.Mapping[d.Tags[i]] = schema.Ref
+		}
+		result.OneOf = append(result.OneOf, *schema)
+	}
+
+	return result, nil
+}
+
+// lengthBounds returns the minimum and maximum length required by the len constraints of
+// the schema, as in len(string) >= 1, or nil if the length is not bound
+func lengthBounds(amlSchema *TypeSchema) (min, max *int64) {
+	for _, constraint := range amlSchema.Constraints {
+		if constraint.Op == MustMatchSchema {
+			if ts, ok := constraint.Right.(*TypeSchema); ok {
+				nestedMin, nestedMax := lengthBounds(ts)
+				min, max = tighter(min, nestedMin, true), tighter(max, nestedMax, false)
+			}
+			continue
+		}
+		if len(constraint.Apply) != 1 || constraint.Apply[0].Op != LenOp || constraint.Right == nil {
+			continue
+		}
+		n, err := ToInt(constraint.Right)
+		if err != nil {
+			continue
+		}
+		switch Operator(constraint.Op) {
+		case GeOp:
+			min = tighter(min, &n, true)
+		case GtOp:
+			n++
+			min = tighter(min, &n, true)
+		case LeOp:
+			max = tighter(max, &n, false)
+		case LtOp:
+			n--
+			max = tighter(max, &n, false)
+		case EqOp:
+			min, max = tighter(min, &n, true), tighter(max, &n, false)
+		}
+	}
+	return min, max
+}
+
+// tighter returns the larger of two minimums or the smaller of two maximums
+func tighter(left, right *int64, minimum bool) *int64 {
+	if left == nil {
+		return right
+	} else if right == nil {
+		return left
+	}
+	if (*right > *left) == minimum {
+		return right
+	}
+	return left
+}
+
+// setDocTags adds the @deprecated, @since and @example tags of a field's doc comment to
+// its JSON schema property. Examples that are not valid JSON are added as strings.
+func setDocTags(property *jsonschema.Property, tags DocTags) {
+	property.Deprecated = tags.Deprecated
+	if tags.DeprecatedMessage != "" {
+		if property.Description != "" {
+			property.Description += "\n\n"
+		}
+		property.Description += "Deprecated: " + tags.DeprecatedMessage
+	}
+	if tags.Since != "" {
+		property.Comment = "since " + tags.Since
+	}
+	for _, example := range tags.Examples {
+		if json.Valid([]byte(example)) {
+			property.Examples = append(property.Examples, json.RawMessage(example))
+		} else if data, err := json.Marshal(example); err == nil {
+			property.Examples = append(property.Examples, data)
+		}
+	}
+}
@@ -73,6 +73,30 @@ func (d Deferred) Div(right Value) (Value, error) {
 	return Div(v, right)
 }
 
+func (d Deferred) Mod(right Value) (Value, error) {
+	v, err := d.Resolve()
+	if err != nil {
+		return nil, err
+	}
+	return Mod(v, right)
+}
+
+func (d Deferred) IntDiv(right Value) (Value, error) {
+	v, err := d.Resolve()
+	if err != nil {
+		return nil, err
+	}
+	return IntDiv(v, right)
+}
+
+func (d Deferred) Pow(right Value) (Value, error) {
+	v, err := d.Resolve()
+	if err != nil {
+		return nil, err
+	}
+	return Pow(v, right)
+}
+
 func (d Deferred) And(right Valuer) (Value, error) {
 	v, err := d.Resolve()
 	if err != nil {
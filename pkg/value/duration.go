@@ -0,0 +1,302 @@
+package value
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Duration is a duration literal such as 30s, 5min or 1h30m. A plain number used in
+// arithmetic with a duration is treated as a number of seconds.
+type Duration string
+
+func NewDuration(d time.Duration) Duration {
+	s := d.String()
+	// time.Duration renders 1h30m as 1h30m0s, drop the zero components
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	// a bare m is milli, so minutes on their own are written as min
+	if strings.HasSuffix(s, "m") && !strings.Contains(s, "h") {
+		s += "in"
+	}
+	return Duration(s)
+}
+
+func (d Duration) Kind() Kind {
+	return NumberKind
+}
+
+func (d Duration) NativeValue() (any, bool, error) {
+	return d, true, nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(d))
+}
+
+func (d Duration) ToDuration() (time.Duration, error) {
+	s := strings.ReplaceAll(string(d), "_", "")
+	return time.ParseDuration(strings.ReplaceAll(s, "min", "m"))
+}
+
+func (d Duration) isValid() bool {
+	_, err := d.ToDuration()
+	return err == nil
+}
+
+var durationUnits = map[string]time.Duration{
+	"ns":  time.Nanosecond,
+	"us":  time.Microsecond,
+	"ms":  time.Millisecond,
+	"s":   time.Second,
+	"m":   time.Minute,
+	"min": time.Minute,
+	"h":   time.Hour,
+}
+
+// ToUnit returns the duration as a plain number of the given unit (h, m, s, ms, us or ns)
+func (d Duration) ToUnit(unit string) (Value, error) {
+	td, err := d.ToDuration()
+	if err != nil {
+		return nil, err
+	}
+	u, ok := durationUnits[unit]
+	if !ok {
+		return nil, fmt.Errorf("invalid duration unit %q", unit)
+	}
+	if td%u == 0 {
+		return NewValue(int64(td / u)), nil
+	}
+	return NewValue(float64(td) / float64(u)), nil
+}
+
+// ToInt returns the duration as a whole number of seconds
+func (d Duration) ToInt() (int64, error) {
+	td, err := d.ToDuration()
+	if err != nil {
+		return 0, err
+	}
+	if td%time.Second != 0 {
+		return 0, fmt.Errorf("duration %s is not a whole number of seconds", d)
+	}
+	return int64(td / time.Second), nil
+}
+
+// ToFloat returns the duration in seconds
+func (d Duration) ToFloat() (float64, error) {
+	td, err := d.ToDuration()
+	if err != nil {
+		return 0, err
+	}
+	return td.Seconds(), nil
+}
+
+// toDuration converts the right operand of an operation with a duration, plain
+// numbers being seconds
+func toDuration(right Value, opName string) (time.Duration, error) {
+	switch v := right.(type) {
+	case Duration:
+		return v.ToDuration()
+	case Number:
+		f, err := v.ToFloat()
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(f * float64(time.Second)), nil
+	case Quantity:
+		return 0, fmt.Errorf("can not %s duration and quantity", opName)
+	}
+	return 0, fmt.Errorf("can not %s duration to invalid kind %s", opName, right.Kind())
+}
+
+func (d Duration) binOp(right Value, opName string, op func(time.Duration, time.Duration) time.Duration) (Value, error) {
+	left, err := d.ToDuration()
+	if err != nil {
+		return nil, err
+	}
+	r, err := toDuration(right, opName)
+	if err != nil {
+		return nil, err
+	}
+	return NewDuration(op(left, r)), nil
+}
+
+func (d Duration) Add(right Value) (Value, error) {
+	return d.binOp(right, "add", func(a, b time.Duration) time.Duration {
+		return a + b
+	})
+}
+
+func (d Duration) Sub(right Value) (Value, error) {
+	return d.binOp(right, "subtract", func(a, b time.Duration) time.Duration {
+		return a - b
+	})
+}
+
+func (d Duration) Mul(right Value) (Value, error) {
+	n, ok := right.(Number)
+	if !ok {
+		return nil, fmt.Errorf("can not multiply duration by kind %s, only by a number", right.Kind())
+	}
+	left, err := d.ToDuration()
+	if err != nil {
+		return nil, err
+	}
+	f, err := n.ToFloat()
+	if err != nil {
+		return nil, err
+	}
+	return NewDuration(time.Duration(float64(left) * f)), nil
+}
+
+func (d Duration) Div(right Value) (Value, error) {
+	left, err := d.ToDuration()
+	if err != nil {
+		return nil, err
+	}
+	switch v := right.(type) {
+	case Number:
+		f, err := v.ToFloat()
+		if err != nil {
+			return nil, err
+		}
+		if f == 0 {
+			return nil, fmt.Errorf("can not divide duration %s by zero", d)
+		}
+		return NewDuration(time.Duration(float64(left) / f)), nil
+	case Duration:
+		r, err := v.ToDuration()
+		if err != nil {
+			return nil, err
+		}
+		return Div(NewValue(int64(left)), NewValue(int64(r)))
+	}
+	return nil, fmt.Errorf("can not divide duration by kind %s", right.Kind())
+}
+
+func (d Duration) Mod(right Value) (Value, error) {
+	if err := checkDivisor(right); err != nil {
+		return nil, err
+	}
+	return d.binOp(right, "modulo", func(a, b time.Duration) time.Duration {
+		return a % b
+	})
+}
+
+// IntDiv divides the duration by a duration, returning a whole number, or by a plain
+// number, returning a duration truncated to whole seconds.
+func (d Duration) IntDiv(right Value) (Value, error) {
+	if err := checkDivisor(right); err != nil {
+		return nil, err
+	}
+	left, err := d.ToDuration()
+	if err != nil {
+		return nil, err
+	}
+	switch v := right.(type) {
+	case Number:
+		f, err := v.ToFloat()
+		if err != nil {
+			return nil, err
+		}
+		return NewDuration(time.Duration(float64(left) / f).Truncate(time.Second)), nil
+	case Duration:
+		r, err := v.ToDuration()
+		if err != nil {
+			return nil, err
+		}
+		return NewValue(int64(left / r)), nil
+	}
+	return nil, fmt.Errorf("can not divide duration by kind %s", right.Kind())
+}
+
+// Pow is not supported as the result would not be a duration
+func (d Duration) Pow(right Value) (Value, error) {
+	return nil, fmt.Errorf("unsupported operator %s on duration", PowOp)
+}
+
+// reverseOp handles a plain number on the left hand side of an operation with a duration
+func (d Duration) reverseOp(op Operator, left Number) (Value, error) {
+	switch op {
+	case AddOp:
+		return d.Add(left)
+	case SubOp:
+		l, err := toDuration(left, "subtract")
+		if err != nil {
+			return nil, err
+		}
+		return NewDuration(l).Sub(d)
+	case MulOp:
+		return d.Mul(left)
+	case DivOp, ModOp, IDivOp:
+		l, err := toDuration(left, "divide")
+		if err != nil {
+			return nil, err
+		}
+		return BinaryOperation(op, NewDuration(l), func() (Value, error) {
+			return d, nil
+		})
+	}
+	return nil, fmt.Errorf("unsupported operator %s with number and duration", op)
+}
+
+func (d Duration) compare(right Value, op func(time.Duration, time.Duration) bool) (Value, error) {
+	left, err := d.ToDuration()
+	if err != nil {
+		return nil, err
+	}
+	r, err := toDuration(right, "compare")
+	if err != nil {
+		return nil, err
+	}
+	return NewValue(op(left, r)), nil
+}
+
+func (d Duration) Lt(right Value) (Value, error) {
+	return d.compare(right, func(a, b time.Duration) bool {
+		return a < b
+	})
+}
+
+func (d Duration) Gt(right Value) (Value, error) {
+	return d.compare(right, func(a, b time.Duration) bool {
+		return a > b
+	})
+}
+
+func (d Duration) Le(right Value) (Value, error) {
+	return d.compare(right, func(a, b time.Duration) bool {
+		return a <= b
+	})
+}
+
+func (d Duration) Ge(right Value) (Value, error) {
+	return d.compare(right, func(a, b time.Duration) bool {
+		return a >= b
+	})
+}
+
+func (d Duration) Eq(right Value) (Value, error) {
+	switch right.(type) {
+	case Duration, Number:
+		return d.compare(right, func(a, b time.Duration) bool {
+			return a == b
+		})
+	}
+	return False, nil
+}
+
+func (d Duration) Neq(right Value) (Value, error) {
+	switch right.(type) {
+	case Duration, Number:
+		return d.compare(right, func(a, b time.Duration) bool {
+			return a != b
+		})
+	}
+	return True, nil
+}
@@ -0,0 +1,293 @@
+package value
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Extend returns the schema derived from base by the fields of derived. Fields that are
+// only in base are inherited and fields that are only in derived are added. Fields that
+// are in both must narrow the field of base: they can tighten its constraints or change
+// its default, but can not change its kind, make a required field optional, loosen a
+// comparison bound, add an alternate or allow new keys in an object that does not.
+func Extend(ctx context.Context, base, derived Schema) (Schema, error) {
+	left, ok := base.(*TypeSchema)
+	if !ok {
+		return nil, fmt.Errorf("can not extend schema %T", base)
+	}
+	right, ok := derived.(*TypeSchema)
+	if !ok {
+		return nil, fmt.Errorf("can not extend schema with %T", derived)
+	}
+	return left.resolve().refine(ctx, right.resolve())
+}
+
+// refine returns the schema n narrowed by right. The constraints of n still apply to the
+// result.
+func (n *TypeSchema) refine(ctx context.Context, right *TypeSchema) (*TypeSchema, error) {
+	if n.TargetKind() != UnionKind && n.TargetKind() != right.TargetKind() {
+		return nil, fmt.Errorf("can not change kind %s to %s", n.TargetKind(), right.TargetKind())
+	}
+	if err := n.checkWiden(right); err != nil {
+		return nil, err
+	}
+	if err := n.checkAlternates(ctx, right); err != nil {
+		return nil, err
+	}
+
+	def, hasDefault, err := right.DefaultWithImplicit(false)
+	if err != nil {
+		return nil, err
+	}
+	if hasDefault {
+		if _, err := n.Validate(ctx, def); err != nil {
+			return nil, fmt.Errorf("default %s does not match the inherited schema: %w", def, err)
+		}
+	}
+
+	if n.Object != nil && right.Object != nil {
+		obj, err := n.Object.refine(ctx, right.Object)
+		if err != nil {
+			return nil, err
+		}
+		result := *right
+		result.Object = obj
+		result.Constraints = append(slices.Clone(right.Constraints), n.Constraints...)
+		if result.DefaultValue == nil {
+			result.DefaultValue = n.DefaultValue
+		}
+		return &result, nil
+	}
+
+	result := &TypeSchema{
+		Positions: mergePositions(n.Positions, right.Positions),
+		KindValue: right.TargetKind(),
+		Path:      right.Path,
+		Constraints: []Constraint{
+			{
+				Op:    MustMatchSchema,
+				Right: right,
+			},
+			{
+				Op:    MustMatchSchema,
+				Right: n,
+			},
+		},
+	}
+	if hasDefault {
+		// the default of right replaces the default of n
+		result.DefaultValue = def
+	}
+	return result, nil
+}
+
+// checkWiden returns an error if a comparison bound of right is looser than a bound of n
+// in the same direction on the same value, such as len(value) < 100 over len(value) < 5.
+// The bound of n would still apply, so the wider bound would be silently ignored.
+func (n *TypeSchema) checkWiden(right *TypeSchema) error {
+	for _, r := range right.bounds() {
+		for _, l := range n.bounds() {
+			if operand(r) != operand(l) {
+				continue
+			}
+			looser, err := isLooser(r, l)
+			if err != nil {
+				return err
+			}
+			if looser {
+				return fmt.Errorf("can not widen constraint [%s %s %s] to [%s %s %s]",
+					operand(l), l.Op, l.Right, operand(r), r.Op, r.Right)
+			}
+		}
+	}
+	return nil
+}
+
+// bounds returns the <, <=, > and >= constraints of n, including those of the schemas n
+// must match
+func (n *TypeSchema) bounds() (result []Constraint) {
+	for _, c := range n.Constraints {
+		switch Operator(c.Op) {
+		case LtOp, LeOp, GtOp, GeOp:
+			if c.Right.Kind() == NumberKind {
+				result = append(result, c)
+			}
+		case MustMatchSchema:
+			if ts, ok := c.Right.(*TypeSchema); ok {
+				result = append(result, ts.resolve().bounds()...)
+			}
+		}
+	}
+	return result
+}
+
+// operand returns the text of the value a constraint is checked against, as in value
+// or len(value)
+func operand(c Constraint) string {
+	result := "value"
+	for _, operation := range c.Apply {
+		result = operation.format(result)
+	}
+	return result
+}
+
+// checkAlternates returns an error if right allows an alternate that n does not, such as
+// enum("a", "b", "c") over enum("a", "b"). Literal alternates must be valid for n and other
+// alternates must have the kind of an alternate of n.
+func (n *TypeSchema) checkAlternates(ctx context.Context, right *TypeSchema) error {
+	left := n.alternates()
+	if len(left) == 0 {
+		return nil
+	}
+	kinds := map[Kind]bool{}
+	for _, alt := range left {
+		kinds[alt.TargetKind()] = true
+	}
+	for _, alt := range right.alternates() {
+		if v, ok := alt.literal(); ok {
+			if _, err := n.Validate(ctx, v); err != nil {
+				return fmt.Errorf("can not widen alternates to allow [value == %s]", v)
+			}
+		} else if !kinds[alt.TargetKind()] {
+			return fmt.Errorf("can not widen alternates to allow kind %s", alt.TargetKind())
+		}
+	}
+	return nil
+}
+
+// alternates returns the alternates of n, flattening nested alternates and including
+// those of the schemas n must match
+func (n *TypeSchema) alternates() (result []*TypeSchema) {
+	for _, c := range n.Constraints {
+		if ts, ok := c.Right.(*TypeSchema); ok && c.Op == MustMatchSchema {
+			result = append(result, ts.resolve().alternates()...)
+		}
+	}
+	for _, alt := range n.Alternates {
+		ts, ok := alt.(*TypeSchema)
+		if !ok {
+			continue
+		}
+		ts = ts.resolve()
+		if nested := ts.alternates(); len(nested) > 0 {
+			result = append(result, nested...)
+		} else {
+			result = append(result, ts)
+		}
+	}
+	return result
+}
+
+// literal returns the value of an alternate that only matches a single value, as the
+// alternates of enum("a", "b") do
+func (n *TypeSchema) literal() (Value, bool) {
+	if n.DefaultValue != nil && len(n.Constraints) == 0 && n.Object == nil && n.Array == nil {
+		return n.DefaultValue, true
+	}
+	for _, c := range n.Constraints {
+		if Operator(c.Op) == EqOp && len(c.Apply) == 0 {
+			return c.Right, true
+		}
+	}
+	return nil, false
+}
+
+func isUpperBound(c Constraint) bool {
+	return Operator(c.Op) == LtOp || Operator(c.Op) == LeOp
+}
+
+func isInclusive(c Constraint) bool {
+	return Operator(c.Op) == LeOp || Operator(c.Op) == GeOp
+}
+
+// isLooser returns true if the bound r allows values that the bound l in the same
+// direction does not
+func isLooser(r, l Constraint) (bool, error) {
+	if isUpperBound(r) != isUpperBound(l) {
+		return false, nil
+	}
+	eq, err := Eq(r.Right, l.Right)
+	if err != nil {
+		return false, err
+	}
+	if b, err := ToBool(eq); err != nil {
+		return false, err
+	} else if b {
+		// only an inclusive bound is looser than an exclusive bound of the same value
+		return isInclusive(r) && !isInclusive(l), nil
+	}
+	cmp := Gt
+	if !isUpperBound(r) {
+		cmp = Lt
+	}
+	v, err := cmp(r.Right, l.Right)
+	if err != nil {
+		return false, err
+	}
+	return ToBool(v)
+}
+
+// refine returns the object schema n with the fields of right added or narrowing the
+// fields of n with the same key
+func (n *ObjectSchema) refine(ctx context.Context, right *ObjectSchema) (*ObjectSchema, error) {
+	if right.AllowNewKeys && !n.AllowNewKeys {
+		return nil, fmt.Errorf("can not allow new keys in an object that does not allow them")
+	}
+
+	result := &ObjectSchema{
+		Positions:    mergePositions(n.Positions, right.Positions),
+		AllowNewKeys: right.AllowNewKeys,
+		Description:  right.Description,
+	}
+	if result.Description == "" {
+		result.Description = n.Description
+	}
+
+	existing := map[string]int{}
+	for _, field := range n.Fields {
+		existing[field.Key] = len(result.Fields)
+		result.Fields = append(result.Fields, field)
+	}
+
+	for _, rightField := range right.Fields {
+		i, ok := existing[rightField.Key]
+		if !ok {
+			result.Fields = append(result.Fields, rightField)
+			continue
+		}
+
+		leftField := result.Fields[i]
+		if !leftField.Optional && rightField.Optional {
+			return nil, fmt.Errorf("invalid extension of field %s: can not make a required field optional", rightField.Key)
+		}
+
+		leftSchema, leftOK := leftField.Schema.(*TypeSchema)
+		rightSchema, rightOK := rightField.Schema.(*TypeSchema)
+		if !leftOK || !rightOK {
+			return nil, fmt.Errorf("invalid extension of field %s: can not extend schema %T with %T",
+				rightField.Key, leftField.Schema, rightField.Schema)
+		}
+
+		schema, err := leftSchema.resolve().refine(ctx, rightSchema.resolve())
+		if err != nil {
+			return nil, fmt.Errorf("invalid extension of field %s: %w", rightField.Key, err)
+		}
+
+		description := rightField.Description
+		if description == "" {
+			description = leftField.Description
+		}
+		result.Fields[i] = ObjectSchemaField{
+			Key:         rightField.Key,
+			Match:       rightField.Match,
+			Optional:    rightField.Optional,
+			Description: description,
+			DocTags:     leftField.DocTags.Merge(rightField.DocTags),
+			Attributes:  leftField.Attributes.Merge(rightField.Attributes),
+			Schema:      schema,
+		}
+	}
+
+	return result, nil
+}
@@ -27,6 +27,7 @@ const (
 	LoopControlPriority = RightMergePriority(10)
 	DefaultedPriority   = RightMergePriority(0)
 	TypeSchemaPriority  = RightMergePriority(5)
+	OverridePriority    = RightMergePriority(8)
 )
 
 type RightMerger interface {
@@ -0,0 +1,104 @@
+package value
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ValidationMode selects how Validate treats the fields of objects that are missing
+type ValidationMode string
+
+const (
+	// ValidateAndDefault fills in the defaults of missing fields and fails if a required
+	// field without a default is missing. It is the mode if none is set.
+	ValidateAndDefault = ValidationMode("")
+	// ValidateStrict checks the value as written. Defaults are not filled in, and a missing
+	// field with a default is left out rather than reported as missing.
+	ValidateStrict = ValidationMode("strict")
+	// ValidateDefaultsOnly fills in the defaults of missing fields and ignores the missing
+	// required fields that have no default.
+	ValidateDefaultsOnly = ValidationMode("defaults-only")
+	// ValidateReportMissing fills in the defaults of missing fields and reports every
+	// object of the value that is missing required fields rather than only the first.
+	ValidateReportMissing = ValidationMode("report-missing")
+)
+
+var validationModes = []ValidationMode{
+	ValidateAndDefault,
+	ValidateStrict,
+	ValidateDefaultsOnly,
+	ValidateReportMissing,
+}
+
+func ParseValidationMode(s string) (ValidationMode, error) {
+	for _, mode := range validationModes {
+		if string(mode) == s {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("invalid validation mode %q, expected one of %q, %q or %q", s,
+		ValidateStrict, ValidateDefaultsOnly, ValidateReportMissing)
+}
+
+type (
+	validationModeKey struct{}
+	missingKeysKey    struct{}
+)
+
+func WithValidationMode(ctx context.Context, mode ValidationMode) context.Context {
+	return context.WithValue(ctx, validationModeKey{}, mode)
+}
+
+func GetValidationMode(ctx context.Context) ValidationMode {
+	mode, _ := ctx.Value(validationModeKey{}).(ValidationMode)
+	return mode
+}
+
+// alternateContext returns the context to validate the alternates of a union with. The
+// alternate that is used is the first one without an error, so missing required fields
+// must be errors while the alternates are tried.
+func alternateContext(ctx context.Context) context.Context {
+	switch GetValidationMode(ctx) {
+	case ValidateDefaultsOnly, ValidateReportMissing:
+		return WithValidationMode(ctx, ValidateAndDefault)
+	}
+	return ctx
+}
+
+// missingKeys collects the missing required fields in the ValidateReportMissing mode
+type missingKeys struct {
+	errs []error
+}
+
+// withMissingKeys returns a context to collect the missing required fields of a value
+// in, unless the mode is not ValidateReportMissing or they are already collected
+func withMissingKeys(ctx context.Context) (context.Context, *missingKeys) {
+	if GetValidationMode(ctx) != ValidateReportMissing || ctx.Value(missingKeysKey{}) != nil {
+		return ctx, nil
+	}
+	result := &missingKeys{}
+	return context.WithValue(ctx, missingKeysKey{}, result), result
+}
+
+// reportMissingKeys records an error for each key of err and returns true if the missing
+// fields of the value are being collected. The paths of each error end in the key, so
+// that a field of the root object is reported with a path like any other field.
+func reportMissingKeys(ctx context.Context, err *ErrMissingRequiredKeys) bool {
+	collector, ok := ctx.Value(missingKeysKey{}).(*missingKeys)
+	if !ok || GetValidationMode(ctx) != ValidateReportMissing {
+		return false
+	}
+	for _, key := range err.Keys {
+		collector.errs = append(collector.errs, &ErrMissingRequiredKeys{
+			DataPath:   err.DataPath.withKey(key),
+			SchemaPath: err.SchemaPath.withKey(key),
+			Keys:       []string{key},
+		})
+	}
+	return true
+}
+
+func (m *missingKeys) err() error {
+	return errors.Join(m.errs...)
+}
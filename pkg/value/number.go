@@ -31,6 +31,25 @@ func init() {
 		// Ki, Mi, Gi, Ti, Pi
 		multipliers[v+"i"] = binary
 	}
+	// m is milli rather than mega, as in the Kubernetes CPU quantity 500m
+	multipliers["m"] = 1e-3
+}
+
+// scale multiplies f by the multiplier m of a unit. The milli unit divides instead so
+// that 9m is exactly 0.009.
+func scale(f, m float64) float64 {
+	if m < 1 {
+		return f / math.Round(1/m)
+	}
+	return f * m
+}
+
+// unscale divides f by the multiplier m of a unit, the inverse of scale
+func unscale(f, m float64) float64 {
+	if m < 1 {
+		return f * math.Round(1/m)
+	}
+	return f / m
 }
 
 func (n Number) Kind() Kind {
@@ -59,6 +78,12 @@ func toNum(n Value) (reti *int64, retf *float64, err error) {
 	return
 }
 
+// reverseOper is implemented by numbers with a unit, such as Quantity and Duration, so
+// that an operation with a plain number on the left hand side keeps the unit.
+type reverseOper interface {
+	reverseOp(op Operator, left Number) (Value, error)
+}
+
 func (n Number) binCompare(right Value, opName string, intFunc func(int64, int64) bool, floatFunc func(float64, float64) bool) (Value, error) {
 	if right.Kind() != NumberKind {
 		return nil, fmt.Errorf("can not compare (%s) number to invalid kind %s", opName, right.Kind())
@@ -108,6 +133,9 @@ func (n Number) binOp(right Value, opName string, intFunc func(int64, int64) any
 }
 
 func (n Number) Sub(right Value) (Value, error) {
+	if u, ok := right.(reverseOper); ok {
+		return u.reverseOp(SubOp, n)
+	}
 	return n.binOp(right, "subtract", func(i int64, i2 int64) any {
 		return i - i2
 	}, func(f float64, f2 float64) float64 {
@@ -116,6 +144,9 @@ func (n Number) Sub(right Value) (Value, error) {
 }
 
 func (n Number) Add(right Value) (Value, error) {
+	if u, ok := right.(reverseOper); ok {
+		return u.reverseOp(AddOp, n)
+	}
 	return n.binOp(right, "add", func(i int64, i2 int64) any {
 		return i + i2
 	}, func(f float64, f2 float64) float64 {
@@ -124,6 +155,9 @@ func (n Number) Add(right Value) (Value, error) {
 }
 
 func (n Number) Mul(right Value) (Value, error) {
+	if u, ok := right.(reverseOper); ok {
+		return u.reverseOp(MulOp, n)
+	}
 	return n.binOp(right, "multiply", func(i int64, i2 int64) any {
 		return i * i2
 	}, func(f float64, f2 float64) float64 {
@@ -132,6 +166,12 @@ func (n Number) Mul(right Value) (Value, error) {
 }
 
 func (n Number) Div(right Value) (Value, error) {
+	if u, ok := right.(reverseOper); ok {
+		return u.reverseOp(DivOp, n)
+	}
+	if err := checkDivisor(right); err != nil {
+		return nil, err
+	}
 	return n.binOp(right, "divide", func(i int64, i2 int64) any {
 		if i%i2 == 0 {
 			return i / i2
@@ -142,6 +182,87 @@ func (n Number) Div(right Value) (Value, error) {
 	})
 }
 
+// Mod returns the remainder of n divided by right, which has the sign of n
+func (n Number) Mod(right Value) (Value, error) {
+	if u, ok := right.(reverseOper); ok {
+		return u.reverseOp(ModOp, n)
+	}
+	if err := checkDivisor(right); err != nil {
+		return nil, err
+	}
+	return n.binOp(right, "modulo", func(i int64, i2 int64) any {
+		return i % i2
+	}, math.Mod)
+}
+
+// IntDiv divides n by right and truncates the result towards zero. The result is an
+// int for int operands and a float without a fraction otherwise.
+func (n Number) IntDiv(right Value) (Value, error) {
+	if u, ok := right.(reverseOper); ok {
+		return u.reverseOp(IDivOp, n)
+	}
+	if err := checkDivisor(right); err != nil {
+		return nil, err
+	}
+	return n.binOp(right, "divide", func(i int64, i2 int64) any {
+		return i / i2
+	}, func(f float64, f2 float64) float64 {
+		return math.Trunc(f / f2)
+	})
+}
+
+// Pow raises n to the power of right. The result is an int for an int base and a
+// non-negative int exponent unless it overflows, otherwise a float.
+func (n Number) Pow(right Value) (Value, error) {
+	if u, ok := right.(reverseOper); ok {
+		return u.reverseOp(PowOp, n)
+	}
+	return n.binOp(right, "raise", func(i int64, i2 int64) any {
+		if i2 >= 0 {
+			if result, ok := intPow(i, i2); ok {
+				return result
+			}
+		}
+		return math.Pow(float64(i), float64(i2))
+	}, math.Pow)
+}
+
+// intPow returns base**exp for a non-negative exp, or false if the result overflows
+func intPow(base, exp int64) (int64, bool) {
+	switch base {
+	case 0, 1:
+		if exp == 0 {
+			return 1, true
+		}
+		return base, true
+	case -1:
+		if exp%2 == 0 {
+			return 1, true
+		}
+		return -1, true
+	}
+
+	result := int64(1)
+	for ; exp > 0; exp-- {
+		next := result * base
+		if next/base != result {
+			return 0, false
+		}
+		result = next
+	}
+	return result, true
+}
+
+func checkDivisor(right Value) error {
+	if right.Kind() != NumberKind {
+		return nil
+	}
+	if f, err := ToFloat(right); err == nil && f == 0 {
+		return fmt.Errorf("division by zero")
+	}
+	return nil
+}
+
 func (n Number) Lt(right Value) (Value, error) {
 	return n.binCompare(right, "less than", func(i int64, i2 int64) bool {
 		return i < i2
@@ -207,6 +328,16 @@ func extraMultiplierAndNormalize(n string) (string, float64) {
 
 func (n Number) ToInt() (int64, error) {
 	str, m := extraMultiplierAndNormalize(string(n))
+	if m < 1 {
+		f, err := n.ToFloat()
+		if err != nil {
+			return 0, err
+		}
+		if f != math.Trunc(f) {
+			return 0, fmt.Errorf("%s is not a whole number", n)
+		}
+		return int64(f), nil
+	}
 	ret, err := strconv.ParseInt(str, 10, 64)
 	return ret * int64(m), err
 }
@@ -214,7 +345,7 @@ func (n Number) ToInt() (int64, error) {
 func (n Number) ToFloat() (float64, error) {
 	str, m := extraMultiplierAndNormalize(string(n))
 	ret, err := strconv.ParseFloat(str, 64)
-	return ret * m, err
+	return scale(ret, m), err
 }
 
 func (n Number) MarshalJSON() ([]byte, error) {
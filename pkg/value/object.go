@@ -295,6 +295,30 @@ func MergeObjects(left, right Value, allowNewKeys bool, merger ObjectMerge) (mer
 	}, changed, nil
 }
 
+// Override returns the fields of left and right, where the fields of right replace the
+// fields of left with the same key instead of being merged with them. Values that are
+// not objects are merged.
+func Override(left, right Value) (Value, error) {
+	if left == nil {
+		return right, nil
+	}
+
+	if ls, ok := left.(*TypeSchema); ok && ls.Object != nil {
+		if rs, ok := right.(*TypeSchema); ok && rs.Object != nil {
+			return ls.withoutFields(rs.Object.Fields).Merge(rs)
+		}
+	}
+
+	if left.Kind() != ObjectKind || right.Kind() != ObjectKind {
+		return Merge(left, right)
+	}
+
+	v, _, err := MergeObjects(left, right, true, func(_, right Value) (Value, bool, error) {
+		return right, false, nil
+	})
+	return v, err
+}
+
 func (n *Object) Merge(right Value) (Value, error) {
 	if err := AssertKindsMatch(n, right); err != nil {
 		return nil, err
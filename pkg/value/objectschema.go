@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -20,7 +21,36 @@ type ObjectSchemaField struct {
 	Match       bool   `json:"match"`
 	Optional    bool   `json:"optional"`
 	Description string `json:"description"`
-	Schema      Schema `json:"schema"`
+	DocTags
+	Attributes Attributes `json:"attributes,omitempty"`
+	Schema     Schema     `json:"schema"`
+}
+
+// DocTags are the tags of the doc comment of a field, written as lines starting with
+// @deprecated, @example or @since
+type DocTags struct {
+	Deprecated        bool     `json:"deprecated,omitempty"`
+	DeprecatedMessage string   `json:"deprecatedMessage,omitempty"`
+	Since             string   `json:"since,omitempty"`
+	Examples          []string `json:"examples,omitempty"`
+}
+
+func (d DocTags) Merge(right DocTags) DocTags {
+	result := DocTags{
+		Deprecated:        d.Deprecated || right.Deprecated,
+		DeprecatedMessage: mergeDescription(d.DeprecatedMessage, right.DeprecatedMessage),
+		Since:             d.Since,
+		Examples:          slices.Clone(d.Examples),
+	}
+	if right.Since != "" {
+		result.Since = right.Since
+	}
+	for _, example := range right.Examples {
+		if !slices.Contains(result.Examples, example) {
+			result.Examples = append(result.Examples, example)
+		}
+	}
+	return result
 }
 
 func NewOpenObject() *TypeSchema {
@@ -89,6 +119,8 @@ func mergeObjectSchemaFields(left, right *ObjectSchema) (result []ObjectSchemaFi
 				Match:       leftField.Match || rightField.Match,
 				Optional:    leftField.Optional && rightField.Optional,
 				Description: mergeDescription(leftField.Description, rightField.Description),
+				DocTags:     leftField.DocTags.Merge(rightField.DocTags),
+				Attributes:  leftField.Attributes.Merge(rightField.Attributes),
 				Schema:      schema,
 			}
 			result[leftFieldIndex] = mergedField
@@ -283,7 +315,10 @@ func (n *ObjectSchema) Validate(ctx context.Context, right Value, schemaPath Pat
 		})
 	}
 
-	var missingKeys []string
+	var (
+		missingKeys []string
+		mode        = GetValidationMode(ctx)
+	)
 	for _, field := range n.Fields {
 		if field.Match || field.Optional {
 			continue
@@ -294,21 +329,27 @@ func (n *ObjectSchema) Validate(ctx context.Context, right Value, schemaPath Pat
 		if def, hasDefault, err := DefaultValue(field.Schema); err != nil {
 			return nil, err
 		} else if hasDefault {
-			head = append(head, Entry{
-				Key:   field.Key,
-				Value: def,
-			})
+			// strict mode checks the value as written, without the defaults
+			if mode != ValidateStrict {
+				head = append(head, Entry{
+					Key:   field.Key,
+					Value: def,
+				})
+			}
 		} else {
 			missingKeys = append(missingKeys, field.Key)
 		}
 	}
 
-	if len(missingKeys) > 0 {
-		return nil, &ErrMissingRequiredKeys{
+	if len(missingKeys) > 0 && mode != ValidateDefaultsOnly {
+		err := &ErrMissingRequiredKeys{
 			DataPath:   GetDataPath(ctx),
 			SchemaPath: schemaPath,
 			Keys:       missingKeys,
 		}
+		if !reportMissingKeys(ctx, err) {
+			return nil, err
+		}
 	}
 
 	result := &Object{
@@ -3,7 +3,9 @@ package value
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strconv"
+	"strings"
 )
 
 func NewValue(v any) Value {
@@ -273,6 +275,9 @@ const (
 	SubOp  = Operator("-")
 	MulOp  = Operator("*")
 	DivOp  = Operator("/")
+	ModOp  = Operator("%")
+	IDivOp = Operator("div")
+	PowOp  = Operator("**")
 	AndOp  = Operator("&&")
 	OrOp   = Operator("||")
 	LtOp   = Operator("<")
@@ -284,6 +289,8 @@ const (
 	NotOp  = Operator("!")
 	MatOp  = Operator("=~")
 	NmatOp = Operator("!~")
+	InOp   = Operator("in")
+	LenOp  = Operator("len")
 )
 
 type AllUnaryOps interface {
@@ -297,6 +304,9 @@ type AllBinaryOps interface {
 	Suber
 	Muler
 	Diver
+	Moder
+	IntDiver
+	Power
 	DeferredAnder
 	DeferredOrer
 	Lter
@@ -362,6 +372,12 @@ func BinaryOperation(op Operator, left Value, deferredRight Valuer) (Value, erro
 		return Mul(left, right)
 	case DivOp:
 		return Div(left, right)
+	case ModOp:
+		return Mod(left, right)
+	case IDivOp:
+		return IntDiv(left, right)
+	case PowOp:
+		return Pow(left, right)
 	case AndOp:
 		return And(left, deferredRight)
 	case OrOp:
@@ -382,6 +398,8 @@ func BinaryOperation(op Operator, left Value, deferredRight Valuer) (Value, erro
 		return Mat(left, right)
 	case NmatOp:
 		return Nmat(left, right)
+	case InOp:
+		return In(left, right)
 	default:
 		return nil, fmt.Errorf("unsupported operator %s", op)
 	}
@@ -447,6 +465,42 @@ func Div(left, right Value) (Value, error) {
 	return nil, fmt.Errorf("value kind %s does not support / operation", left.Kind())
 }
 
+type Moder interface {
+	Mod(right Value) (Value, error)
+}
+
+func Mod(left, right Value) (Value, error) {
+	moder, ok := left.(Moder)
+	if ok {
+		return moder.Mod(right)
+	}
+	return nil, fmt.Errorf("value kind %s does not support %% operation", left.Kind())
+}
+
+type IntDiver interface {
+	IntDiv(right Value) (Value, error)
+}
+
+func IntDiv(left, right Value) (Value, error) {
+	diver, ok := left.(IntDiver)
+	if ok {
+		return diver.IntDiv(right)
+	}
+	return nil, fmt.Errorf("value kind %s does not support div operation", left.Kind())
+}
+
+type Power interface {
+	Pow(right Value) (Value, error)
+}
+
+func Pow(left, right Value) (Value, error) {
+	power, ok := left.(Power)
+	if ok {
+		return power.Pow(right)
+	}
+	return nil, fmt.Errorf("value kind %s does not support ** operation", left.Kind())
+}
+
 type Ander interface {
 	And(right Value) (Value, error)
 }
@@ -615,6 +669,54 @@ func Nmat(left, right Value) (Value, error) {
 	return nil, fmt.Errorf("value kind %s does not support !~ operation", left.Kind())
 }
 
+// In returns true if right is an array containing left, an object with the key left
+// or a string containing the substring left
+func In(left, right Value) (Value, error) {
+	switch right.Kind() {
+	case ArrayKind:
+		items, err := ToValueArray(right)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if item.Kind() != left.Kind() {
+				continue
+			}
+			eq, err := Eq(item, left)
+			if err != nil {
+				return nil, err
+			}
+			if b, err := ToBool(eq); err != nil {
+				return nil, err
+			} else if b {
+				return True, nil
+			}
+		}
+		return False, nil
+	case ObjectKind:
+		key, err := ToString(left)
+		if err != nil {
+			return nil, err
+		}
+		keys, err := Keys(right)
+		if err != nil {
+			return nil, err
+		}
+		return NewValue(slices.Contains(keys, key)), nil
+	case StringKind:
+		sub, err := ToString(left)
+		if err != nil {
+			return nil, err
+		}
+		s, err := ToString(right)
+		if err != nil {
+			return nil, err
+		}
+		return NewValue(strings.Contains(s, sub)), nil
+	}
+	return nil, fmt.Errorf("value kind %s does not support in operation", right.Kind())
+}
+
 type Keyser interface {
 	Keys() ([]string, error)
 }
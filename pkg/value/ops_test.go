@@ -90,6 +90,27 @@ func TestBinary(t *testing.T) {
 		{op: "!=", left: true, right: true, expect: autogold.Expect(false)},
 		{op: "!=", left: "x", right: "x", expect: autogold.Expect(false)},
 		{op: "!=", left: nil, right: nil, expect: autogold.Expect(false)},
+		{op: "+", left: Quantity("1Gi"), right: Quantity("512Mi"), expect: autogold.Expect(Quantity("1536Mi"))},
+		{op: "/", left: Quantity("1Gi"), right: Quantity("512Mi"), expect: autogold.Expect(Number("2"))},
+		{op: "*", left: 3, right: Quantity("2G"), expect: autogold.Expect(Quantity("6G"))},
+		{op: ">", left: Quantity("1G"), right: Quantity("1Gi"), expect: autogold.Expect(false)},
+		{op: "+", left: Duration("5m"), right: Duration("30s"), expect: autogold.Expect(Duration("5m30s"))},
+		{op: "-", left: 10, right: Duration("30s"), expect: autogold.Expect(Duration("-20s"))},
+		{op: "<", left: Duration("90s"), right: Duration("1h"), expect: autogold.Expect(true)},
+		{op: "in", left: 2, right: []any{1, 2}, expect: autogold.Expect(true)},
+		{op: "in", left: "2", right: []any{1, 2}, expect: autogold.Expect(false)},
+		{op: "in", left: "a", right: map[string]any{"a": 1}, expect: autogold.Expect(true)},
+		{op: "in", left: "b", right: map[string]any{"a": 1}, expect: autogold.Expect(false)},
+		{op: "in", left: "ell", right: "hello", expect: autogold.Expect(true)},
+		{op: "%", left: 7, right: 3, expect: autogold.Expect(Number("1"))},
+		{op: "%", left: -7, right: 3, expect: autogold.Expect(Number("-1"))},
+		{op: "%", left: 7.5, right: 2, expect: autogold.Expect(Number("1.5"))},
+		{op: "div", left: 7, right: 2, expect: autogold.Expect(Number("3"))},
+		{op: "div", left: -7, right: 2, expect: autogold.Expect(Number("-3"))},
+		{op: "div", left: 7.5, right: 2, expect: autogold.Expect(Number("3"))},
+		{op: "**", left: 2, right: 10, expect: autogold.Expect(Number("1024"))},
+		{op: "**", left: 2, right: -1, expect: autogold.Expect(Number("0.5"))},
+		{op: "**", left: 4, right: 0.5, expect: autogold.Expect(Number("2"))},
 	}
 
 	for i, test := range tests {
@@ -3,6 +3,7 @@ package value
 import (
 	"context"
 	"encoding/json"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -70,6 +71,13 @@ func (p Path) MarshalJSON() ([]byte, error) {
 	return json.Marshal(p.String())
 }
 
+// withKey returns a copy of p with the key appended
+func (p Path) withKey(key string) Path {
+	return append(slices.Clone(p), PathElement{
+		Key: &key,
+	})
+}
+
 func (p Path) Equals(right Path) bool {
 	if len(p) != len(right) {
 		return false
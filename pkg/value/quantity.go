@@ -0,0 +1,330 @@
+package value
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	binaryUnits  = []string{"Pi", "Ti", "Gi", "Mi", "Ki"}
+	decimalUnits = []string{"P", "T", "G", "M", "k"}
+)
+
+// Quantity is a number that carries a Kubernetes style unit suffix such as 512Mi
+// or 2G. The original unit is kept when the value is rendered and the result of
+// arithmetic is expressed in the unit of the quantity when it can be done exactly.
+type Quantity string
+
+// ParseNumber converts a number literal to a Number, a Quantity if the literal has a unit
+// suffix, or a Duration if the literal is a duration such as 30s, 5min or 1h30m. A bare m
+// suffix is milli, as in 500m, and only means minutes in a compound duration.
+func ParseNumber(s string) Value {
+	if isDuration(s) {
+		if d := Duration(s); d.isValid() {
+			return d
+		}
+	}
+	if _, unit := Quantity(s).split(); unit != "" {
+		return Quantity(s)
+	}
+	return Number(s)
+}
+
+// isDuration returns true if the number literal s has a duration unit, a bare m suffix
+// being the milli quantity unit rather than minutes
+func isDuration(s string) bool {
+	if strings.IndexAny(s, "hms") <= 0 {
+		return false
+	}
+	return !strings.HasSuffix(s, "m") || strings.IndexAny(s[:len(s)-1], "hms") > 0
+}
+
+func (q Quantity) Kind() Kind {
+	return NumberKind
+}
+
+func (q Quantity) NativeValue() (any, bool, error) {
+	return q, true, nil
+}
+
+func (q Quantity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(q))
+}
+
+// split returns the numeric part and the unit of the quantity
+func (q Quantity) split() (string, string) {
+	s := string(q)
+	for _, l := range []int{2, 1} {
+		if len(s) > l {
+			if _, ok := multipliers[s[len(s)-l:]]; ok {
+				return s[:len(s)-l], s[len(s)-l:]
+			}
+		}
+	}
+	return s, ""
+}
+
+// units returns the unit of the quantity followed by the smaller units of the same
+// family, which are tried in order when rendering the result of an operation.
+func (q Quantity) units() []string {
+	_, unit := q.split()
+	if unit == "" {
+		return nil
+	}
+
+	family := decimalUnits
+	if strings.HasSuffix(unit, "i") {
+		family = binaryUnits
+	}
+
+	result := []string{unit}
+	for _, candidate := range family {
+		if multipliers[candidate] < multipliers[unit] {
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
+
+func (q Quantity) ToInt() (int64, error) {
+	return Number(q).ToInt()
+}
+
+func (q Quantity) ToFloat() (float64, error) {
+	return Number(q).ToFloat()
+}
+
+// base returns the quantity as a plain Number with the unit multiplied out
+func (q Quantity) base() (Number, error) {
+	i, f, err := toNum(q)
+	if err != nil {
+		return "", err
+	}
+	if i != nil {
+		return NewValue(*i).(Number), nil
+	}
+	return NewValue(*f).(Number), nil
+}
+
+// toQuantity expresses the number v in the first unit that can represent it as a
+// whole number. If no unit fits the plain number is returned.
+func toQuantity(v Value, units ...string) (Value, error) {
+	i, f, err := toNum(v)
+	if err != nil {
+		return nil, err
+	}
+	if i == nil && f != nil && *f == math.Trunc(*f) && math.Abs(*f) < math.MaxInt64 {
+		n := int64(*f)
+		i = &n
+	}
+	for _, unit := range units {
+		if multipliers[unit] < 1 {
+			// the milli unit fits any number with up to three decimals
+			if n := unscale(*f, multipliers[unit]); n == math.Trunc(n) {
+				return Quantity(strconv.FormatFloat(n, 'f', -1, 64) + unit), nil
+			}
+		} else if i != nil {
+			m := int64(multipliers[unit])
+			if *i%m == 0 {
+				return Quantity(strconv.FormatInt(*i/m, 10) + unit), nil
+			}
+		}
+	}
+	if i == nil {
+		if len(units) == 0 {
+			return v, nil
+		}
+		return Quantity(strconv.FormatFloat(unscale(*f, multipliers[units[0]]), 'f', -1, 64) + units[0]), nil
+	}
+	return NewValue(*i), nil
+}
+
+// ToUnit converts the quantity to the given unit, the empty string converting to a
+// plain number.
+func (q Quantity) ToUnit(unit string) (Value, error) {
+	base, err := q.base()
+	if err != nil {
+		return nil, err
+	}
+	if unit == "" {
+		return base, nil
+	}
+	m, ok := multipliers[unit]
+	if !ok {
+		return nil, fmt.Errorf("invalid quantity unit %q", unit)
+	}
+	f, err := base.ToFloat()
+	if err != nil {
+		return nil, err
+	}
+	return ParseNumber(strconv.FormatFloat(unscale(f, m), 'f', -1, 64) + unit), nil
+}
+
+// rightBase returns the right operand of an operation with a quantity as a plain number
+func rightBase(right Value, opName string) (Number, error) {
+	switch v := right.(type) {
+	case Quantity:
+		return v.base()
+	case Number:
+		return v, nil
+	case Duration:
+		return "", fmt.Errorf("can not %s quantity and duration", opName)
+	}
+	return "", fmt.Errorf("can not %s quantity to invalid kind %s", opName, right.Kind())
+}
+
+func (q Quantity) binOp(right Value, opName string, op func(Number, Value) (Value, error), units []string) (Value, error) {
+	left, err := q.base()
+	if err != nil {
+		return nil, err
+	}
+	r, err := rightBase(right, opName)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := op(left, r)
+	if err != nil {
+		return nil, err
+	}
+	return toQuantity(ret, units...)
+}
+
+func (q Quantity) Add(right Value) (Value, error) {
+	units := q.units()
+	if rq, ok := right.(Quantity); ok {
+		units = append(units, rq.units()...)
+	}
+	return q.binOp(right, "add", Number.Add, units)
+}
+
+func (q Quantity) Sub(right Value) (Value, error) {
+	units := q.units()
+	if rq, ok := right.(Quantity); ok {
+		units = append(units, rq.units()...)
+	}
+	return q.binOp(right, "subtract", Number.Sub, units)
+}
+
+func (q Quantity) Mul(right Value) (Value, error) {
+	if _, ok := right.(Quantity); ok {
+		return q.binOp(right, "multiply", Number.Mul, nil)
+	}
+	return q.binOp(right, "multiply", Number.Mul, q.units())
+}
+
+func (q Quantity) Div(right Value) (Value, error) {
+	if _, ok := right.(Quantity); ok {
+		return q.binOp(right, "divide", Number.Div, nil)
+	}
+	return q.binOp(right, "divide", Number.Div, q.units())
+}
+
+func (q Quantity) Mod(right Value) (Value, error) {
+	units := q.units()
+	if rq, ok := right.(Quantity); ok {
+		units = append(units, rq.units()...)
+	}
+	return q.binOp(right, "modulo", Number.Mod, units)
+}
+
+func (q Quantity) IntDiv(right Value) (Value, error) {
+	if _, ok := right.(Quantity); ok {
+		return q.binOp(right, "divide", Number.IntDiv, nil)
+	}
+	return q.binOp(right, "divide", Number.IntDiv, q.units())
+}
+
+// Pow is not supported as the unit of the result would not be a unit of the quantity
+func (q Quantity) Pow(right Value) (Value, error) {
+	return nil, fmt.Errorf("unsupported operator %s on quantity", PowOp)
+}
+
+// reverseOp handles a plain number on the left hand side of an operation with a quantity
+func (q Quantity) reverseOp(op Operator, left Number) (Value, error) {
+	switch op {
+	case AddOp:
+		return q.Add(left)
+	case SubOp:
+		base, err := q.base()
+		if err != nil {
+			return nil, err
+		}
+		ret, err := left.Sub(base)
+		if err != nil {
+			return nil, err
+		}
+		return toQuantity(ret, q.units()...)
+	case MulOp:
+		return q.Mul(left)
+	case DivOp:
+		base, err := q.base()
+		if err != nil {
+			return nil, err
+		}
+		return left.Div(base)
+	case ModOp:
+		base, err := q.base()
+		if err != nil {
+			return nil, err
+		}
+		ret, err := left.Mod(base)
+		if err != nil {
+			return nil, err
+		}
+		return toQuantity(ret, q.units()...)
+	case IDivOp:
+		base, err := q.base()
+		if err != nil {
+			return nil, err
+		}
+		return left.IntDiv(base)
+	}
+	return nil, fmt.Errorf("unsupported operator %s on quantity", op)
+}
+
+func (q Quantity) compare(right Value, opName string, op func(Number, Value) (Value, error)) (Value, error) {
+	left, err := q.base()
+	if err != nil {
+		return nil, err
+	}
+	r, err := rightBase(right, opName)
+	if err != nil {
+		return nil, err
+	}
+	return op(left, r)
+}
+
+func (q Quantity) Lt(right Value) (Value, error) {
+	return q.compare(right, "compare", Number.Lt)
+}
+
+func (q Quantity) Gt(right Value) (Value, error) {
+	return q.compare(right, "compare", Number.Gt)
+}
+
+func (q Quantity) Le(right Value) (Value, error) {
+	return q.compare(right, "compare", Number.Le)
+}
+
+func (q Quantity) Ge(right Value) (Value, error) {
+	return q.compare(right, "compare", Number.Ge)
+}
+
+func (q Quantity) Eq(right Value) (Value, error) {
+	switch right.(type) {
+	case Quantity, Number:
+		return q.compare(right, "compare", Number.Eq)
+	}
+	return False, nil
+}
+
+func (q Quantity) Neq(right Value) (Value, error) {
+	switch right.(type) {
+	case Quantity, Number:
+		return q.compare(right, "compare", Number.Neq)
+	}
+	return True, nil
+}
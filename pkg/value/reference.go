@@ -0,0 +1,54 @@
+package value
+
+import (
+	"fmt"
+)
+
+// reference holds the schema that a reference created by NewReference refers to. It is
+// shared by all copies of the reference so that they see the schema once it is defined.
+type reference struct {
+	target *TypeSchema
+}
+
+// NewReference returns a schema that refers to the schema defined at path. The schema it
+// refers to is set by Resolve after it is evaluated, which allows a schema to refer to
+// itself, as in a tree node: {children: [node]}.
+func NewReference(pos Position, path Path) *TypeSchema {
+	return &TypeSchema{
+		Positions: []Position{pos},
+		Path:      path,
+		Reference: true,
+		ref:       &reference{},
+	}
+}
+
+// Resolve sets the schema that the reference n refers to. Values that are not a type
+// schema can not be referred to and are ignored.
+func (n *TypeSchema) Resolve(target Value) error {
+	if n.ref == nil {
+		return fmt.Errorf("schema %s is not a reference", n.Path)
+	}
+	ts, ok := target.(*TypeSchema)
+	if !ok {
+		return nil
+	}
+	if ts.resolve().ref == n.ref {
+		return NewErrPosition(lastPos(n.Positions, nil),
+			fmt.Errorf("invalid circular schema %s, the schema can not be only a reference to itself", n.Path))
+	}
+	n.ref.target = ts
+	return nil
+}
+
+// resolve returns the schema that n refers to, or n if it is not a resolved reference
+func (n *TypeSchema) resolve() *TypeSchema {
+	for n.ref != nil && n.ref.target != nil {
+		n = n.ref.target
+	}
+	return n
+}
+
+// unresolved returns true if n is a reference to a schema that is not yet defined
+func (n *TypeSchema) unresolved() bool {
+	return n.ref != nil && n.ref.target == nil
+}
@@ -0,0 +1,39 @@
+package value
+
+import (
+	"regexp"
+	"sync"
+)
+
+// maxCachedRegexps bounds the size of the compiled pattern cache. When the limit is
+// reached the cache is reset rather than tracking usage.
+const maxCachedRegexps = 1024
+
+var (
+	regexpLock  sync.Mutex
+	regexpCache = map[string]*regexp.Regexp{}
+)
+
+// CompileRegexp compiles the pattern, reusing a previously compiled regexp for the same
+// pattern so that expressions evaluated in loops do not recompile on every iteration.
+func CompileRegexp(pattern string) (*regexp.Regexp, error) {
+	regexpLock.Lock()
+	re, ok := regexpCache[pattern]
+	regexpLock.Unlock()
+	if ok {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	regexpLock.Lock()
+	defer regexpLock.Unlock()
+	if len(regexpCache) >= maxCachedRegexps {
+		regexpCache = map[string]*regexp.Regexp{}
+	}
+	regexpCache[pattern] = re
+	return re, nil
+}
@@ -0,0 +1,20 @@
+package value
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileRegexpCache(t *testing.T) {
+	re, err := CompileRegexp("a+b")
+	require.NoError(t, err)
+
+	again, err := CompileRegexp("a+b")
+	require.NoError(t, err)
+	assert.Same(t, re, again)
+
+	_, err = CompileRegexp("a(")
+	assert.Error(t, err)
+}
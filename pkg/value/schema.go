@@ -24,7 +24,15 @@ func Validate(ctx context.Context, schema Value, v Value) (Value, error) {
 		return undef, nil
 	}
 	if s, ok := schema.(Schema); ok {
-		return s.Validate(ctx, v)
+		ctx, missing := withMissingKeys(ctx)
+		result, err := s.Validate(ctx, v)
+		if err == nil && missing != nil {
+			err = missing.err()
+		}
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
 	}
 	return nil, fmt.Errorf("value kind %s can not be used for validation", v.Kind())
 }
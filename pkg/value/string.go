@@ -2,7 +2,7 @@ package value
 
 import (
 	"fmt"
-	"regexp"
+	"unicode/utf8"
 )
 
 type String string
@@ -15,19 +15,23 @@ func (s String) NativeValue() (any, bool, error) {
 	return (string)(s), true, nil
 }
 
+// Len returns the number of characters in the string, as the minLength and maxLength of
+// JSON schema count them, rather than the number of bytes
 func (s String) Len() (Value, error) {
-	return NewValue(len(s)), nil
+	return NewValue(utf8.RuneCountInString(string(s))), nil
 }
 
+// Index returns the character at the index, counting characters the same way as Len
 func (s String) Index(key Value) (Value, bool, error) {
 	idx, err := ToInt(key)
 	if err != nil {
 		return nil, false, err
 	}
-	if idx < 0 || int(idx) >= len(s) {
-		return nil, false, fmt.Errorf("index %d out of bound, len %d", idx, len(s))
+	runes := []rune(s)
+	if idx < 0 || int(idx) >= len(runes) {
+		return nil, false, fmt.Errorf("index %d out of bound, len %d", idx, len(runes))
 	}
-	return NewValue(s[idx : idx+1]), true, nil
+	return NewValue(string(runes[idx])), true, nil
 }
 
 func (s String) Add(right Value) (Value, error) {
@@ -51,7 +55,7 @@ func (s String) Mat(right Value) (Value, error) {
 	if err != nil {
 		return nil, err
 	}
-	re, err := regexp.Compile(rightString)
+	re, err := CompileRegexp(rightString)
 	if err != nil {
 		return nil, err
 	}
@@ -68,7 +72,7 @@ func (s String) Nmat(right Value) (Value, error) {
 	if err != nil {
 		return nil, err
 	}
-	re, err := regexp.Compile(rightString)
+	re, err := CompileRegexp(rightString)
 	if err != nil {
 		return nil, err
 	}
@@ -107,7 +111,7 @@ func (s String) Match(right Value) (bool, error) {
 		return false, err
 	}
 
-	re, err := regexp.Compile(string(s))
+	re, err := CompileRegexp(string(s))
 	if err != nil {
 		return false, err
 	}
@@ -5,6 +5,10 @@ type Summary struct {
 }
 
 func makeReference(types map[string]Schema, schema Schema) Schema {
+	if ts, ok := schema.(*TypeSchema); ok && ts.ref != nil {
+		schema = ts.resolve()
+	}
+
 	result := &TypeSchema{
 		Path:      schema.GetPath(),
 		Reference: true,
@@ -23,28 +27,52 @@ func makeReference(types map[string]Schema, schema Schema) Schema {
 		return ts
 	}
 
+	// The copy is added to types before the fields so that schemas that refer to
+	// themselves become a reference
 	cp := *ts
+	types[s] = &cp
+
 	if cp.Object != nil {
+		obj := *cp.Object
 		var fields []ObjectSchemaField
-		for _, field := range cp.Object.Fields {
+		for _, field := range obj.Fields {
 			field.Schema = makeReference(types, field.Schema)
 			fields = append(fields, field)
 		}
-		cp.Object.Fields = fields
+		obj.Fields = fields
+		cp.Object = &obj
 	}
 	if cp.Array != nil {
+		arr := *cp.Array
 		var valids []Schema
-		for _, valid := range cp.Array.Valid {
+		for _, valid := range arr.Valid {
 			valids = append(valids, makeReference(types, valid))
 		}
-		cp.Array.Valid = valids
+		arr.Valid = valids
+		cp.Array = &arr
+	}
+	if cp.Alternates != nil {
+		var alternates []Schema
+		for _, alt := range cp.Alternates {
+			alternates = append(alternates, makeReference(types, alt))
+		}
+		cp.Alternates = alternates
 	}
-
-	types[s] = &cp
 
 	return result
 }
 
+// resolve returns the schema in the summary that a reference refers to
+func (s *Summary) resolve(ts *TypeSchema) *TypeSchema {
+	ts = ts.resolve()
+	if ts.Reference {
+		if target, ok := s.Types[ts.Path.String()].(*TypeSchema); ok {
+			return target
+		}
+	}
+	return ts
+}
+
 func Summarize(obj Schema) *Summary {
 	result := &Summary{
 		Types: map[string]Schema{},
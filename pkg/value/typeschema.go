@@ -19,7 +19,11 @@ type TypeSchema struct {
 	Path         Path          `json:"path"`
 	Reference    bool          `json:"reference"`
 
+	// apply are the operations of an expression such as int % 2 that are applied to the
+	// value by the next constraint
+	apply     []Operation
 	rendering bool
+	ref       *reference
 }
 
 func (n *TypeSchema) GetPositions() []Position {
@@ -27,6 +31,7 @@ func (n *TypeSchema) GetPositions() []Position {
 }
 
 func (n *TypeSchema) ValidArrayItems() (result []Schema) {
+	n = n.resolve()
 	if n.Array == nil {
 		panic("Array is nil")
 	}
@@ -43,6 +48,7 @@ func (n *TypeSchema) GetPath() Path {
 }
 
 func (n *TypeSchema) Call(ctx context.Context, args []CallArgument) (Value, bool, error) {
+	n = n.resolve()
 	if n.KindValue == FuncKind && n.FuncSchema != nil && n.DefaultValue != nil {
 		return Call(ctx, n.DefaultValue, args...)
 	}
@@ -58,6 +64,7 @@ func (n *TypeSchema) Call(ctx context.Context, args []CallArgument) (Value, bool
 }
 
 func (n *TypeSchema) Keys() (result []string, _ error) {
+	n = n.resolve()
 	if n.Object == nil {
 		return nil, nil
 	}
@@ -71,6 +78,7 @@ func (n *TypeSchema) Keys() (result []string, _ error) {
 }
 
 func (n *TypeSchema) LookupValue(key Value) (Value, bool, error) {
+	n = n.resolve()
 	if n.Object == nil {
 		return nil, false, nil
 	}
@@ -115,9 +123,9 @@ type Condition func(val Value) (Value, error)
 func (n *TypeSchema) String() string {
 	pathString := n.Path.String()
 	if pathString != "" {
-		return fmt.Sprintf("(%s %s %s)", n.KindValue, SchemaKind, pathString)
+		return fmt.Sprintf("(%s %s %s)", n.TargetKind(), SchemaKind, pathString)
 	}
-	return fmt.Sprintf("(%s %s)", n.KindValue, SchemaKind)
+	return fmt.Sprintf("(%s %s)", n.TargetKind(), SchemaKind)
 }
 
 func (n *TypeSchema) Kind() Kind {
@@ -125,7 +133,7 @@ func (n *TypeSchema) Kind() Kind {
 }
 
 func (n *TypeSchema) TargetKind() Kind {
-	return n.KindValue
+	return n.resolve().KindValue
 }
 
 func (n *TypeSchema) Eq(right Value) (Value, error) {
@@ -133,73 +141,88 @@ func (n *TypeSchema) Eq(right Value) (Value, error) {
 		_, err := n.Merge(right)
 		return NewValue(err == nil), nil
 	}
-	result := *n
-	result.Constraints = append(result.Constraints, Constraint{
-		Op:    "==",
-		Right: right,
-	})
-	return &result, nil
+	return n.constrain(EqOp, right), nil
 }
 
 func (n *TypeSchema) Neq(right Value) (Value, error) {
-	result := *n
-	result.Constraints = append(result.Constraints, Constraint{
-		Op:    "!=",
-		Right: right,
-	})
-	return &result, nil
+	return n.constrain(NeqOp, right), nil
 }
 
 func (n *TypeSchema) Gt(right Value) (Value, error) {
-	result := *n
-	result.Constraints = append(result.Constraints, Constraint{
-		Op:    ">",
-		Right: right,
-	})
-	return &result, nil
+	return n.constrain(GtOp, right), nil
 }
 
 func (n *TypeSchema) Ge(right Value) (Value, error) {
-	result := *n
-	result.Constraints = append(result.Constraints, Constraint{
-		Op:    ">=",
-		Right: right,
-	})
-	return &result, nil
+	return n.constrain(GeOp, right), nil
 }
 
 func (n *TypeSchema) Le(right Value) (Value, error) {
-	result := *n
-	result.Constraints = append(result.Constraints, Constraint{
-		Op:    "<=",
-		Right: right,
-	})
-	return &result, nil
+	return n.constrain(LeOp, right), nil
 }
 
 func (n *TypeSchema) Lt(right Value) (Value, error) {
+	return n.constrain(LtOp, right), nil
+}
+
+func (n *TypeSchema) Mat(right Value) (Value, error) {
+	return n.constrain(MatOp, right), nil
+}
+
+func (n *TypeSchema) Nmat(right Value) (Value, error) {
+	return n.constrain(NmatOp, right), nil
+}
+
+func (n *TypeSchema) Mod(right Value) (Value, error) {
+	return n.operation(ModOp, right)
+}
+
+func (n *TypeSchema) IntDiv(right Value) (Value, error) {
+	return n.operation(IDivOp, right)
+}
+
+func (n *TypeSchema) Pow(right Value) (Value, error) {
+	return n.operation(PowOp, right)
+}
+
+// constrain returns a copy of the schema with a constraint that the value, after the
+// pending operations are applied, compares to right with op
+func (n *TypeSchema) constrain(op Operator, right Value) Value {
 	result := *n
 	result.Constraints = append(result.Constraints, Constraint{
-		Op:    "<",
+		Op:    string(op),
 		Right: right,
+		Apply: n.apply,
 	})
-	return &result, nil
+	result.apply = nil
+	return &result
 }
 
-func (n *TypeSchema) Mat(right Value) (Value, error) {
+// operation returns a copy of the schema that applies op to the value before it is
+// checked by the next constraint
+func (n *TypeSchema) operation(op Operator, right Value) (Value, error) {
+	// the len of a string, array or object is a number
+	if n.KindValue != NumberKind && !slices.ContainsFunc(n.apply, func(o Operation) bool { return o.Op == LenOp }) {
+		return nil, fmt.Errorf("schema kind %s does not support %s operation", n.KindValue, op)
+	}
 	result := *n
-	result.Constraints = append(result.Constraints, Constraint{
-		Op:    "=~",
+	result.apply = append(slices.Clone(n.apply), Operation{
+		Op:    op,
 		Right: right,
 	})
 	return &result, nil
 }
 
-func (n *TypeSchema) Nmat(right Value) (Value, error) {
+// Len returns a copy of the schema that applies len to the value before it is checked
+// by the next constraint, as in len(string) <= 63
+func (n *TypeSchema) Len() (Value, error) {
+	switch n.TargetKind() {
+	case StringKind, ArrayKind, ObjectKind:
+	default:
+		return nil, fmt.Errorf("schema kind %s does not support len operation", n.TargetKind())
+	}
 	result := *n
-	result.Constraints = append(result.Constraints, Constraint{
-		Op:    "!~",
-		Right: right,
+	result.apply = append(slices.Clone(n.apply), Operation{
+		Op: LenOp,
 	})
 	return &result, nil
 }
@@ -320,6 +343,7 @@ func (n *TypeSchema) Default() (Value, bool, error) {
 }
 
 func (n *TypeSchema) DefaultWithImplicit(renderImplicit bool) (Value, bool, error) {
+	n = n.resolve()
 	v, ok, err := n.getDefault(false)
 	if err != nil || ok {
 		return v, ok, err
@@ -359,7 +383,9 @@ func (n *TypeSchema) getDefault(renderImplicit bool) (Value, bool, error) {
 
 	if renderImplicit {
 		if n.rendering {
-			return nil, false, fmt.Errorf("invalid circular schema, can not render default (%s)", lastPos(n.Positions, nil))
+			// a schema that contains itself has no implied default, so the field that
+			// refers to it is required, the same as a reference to a recursive schema
+			return nil, false, nil
 		}
 		n.rendering = true
 		defer func() {
@@ -457,6 +483,11 @@ func (e *ErrUnmatchedType) Error() string {
 func checkType(ctx context.Context, schema *TypeSchema, right Value) (Value, error) {
 	var errs []error
 
+	if len(schema.apply) > 0 {
+		return nil, fmt.Errorf("schema with operation %s must be compared to a value, as in int %% 2 == 0",
+			schema.apply[0].format("value"))
+	}
+
 	if TargetCompatible(schema, right) || schema.TargetKind() == UnionKind {
 		if schema.Object != nil {
 			v, err := schema.Object.Validate(ctx, right, schema.Path)
@@ -493,13 +524,18 @@ func checkType(ctx context.Context, schema *TypeSchema, right Value) (Value, err
 		return right, nil
 	}
 
+	// A discriminated union only reports the errors of the alternate selected by the tag
+	if v, ok, err := schema.validateDiscriminated(ctx, right); ok || err != nil {
+		return v, err
+	}
+
 	retErr := &ErrUnmatchedType{
 		Position: lastPos(schema.Positions, nil),
 		Errs:     errs,
 	}
 
 	for _, alt := range schema.Alternates {
-		ret, newErr := alt.Validate(ctx, right)
+		ret, newErr := alt.Validate(alternateContext(ctx), right)
 		if newErr == nil {
 			return ret, nil
 		}
@@ -514,13 +550,29 @@ func (n *TypeSchema) RightMergePriority() RightMergePriority {
 }
 
 func (n *TypeSchema) RightMerge(right Value) (Value, error) {
+	n = n.resolve()
 	if ts, ok := right.(*TypeSchema); ok {
 		return ts.MergeType(n)
 	}
 	return n.Merge(right)
 }
 
+// withoutFields returns a copy of the object schema without the fields that have the
+// same key as one of fields
+func (n *TypeSchema) withoutFields(fields []ObjectSchemaField) *TypeSchema {
+	result := *n
+	obj := *n.Object
+	obj.Fields = slices.DeleteFunc(slices.Clone(obj.Fields), func(field ObjectSchemaField) bool {
+		return slices.ContainsFunc(fields, func(other ObjectSchemaField) bool {
+			return !field.Match && !other.Match && field.Key == other.Key
+		})
+	})
+	result.Object = &obj
+	return &result
+}
+
 func (n *TypeSchema) Merge(right Value) (Value, error) {
+	n = n.resolve()
 	if ts, ok := right.(*TypeSchema); ok {
 		return n.MergeType(ts)
 	}
@@ -540,6 +592,11 @@ func (n *TypeSchema) MergeType(rightSchema Schema) (Schema, error) {
 		return nil, fmt.Errorf("Can not merge incompatible go structs %T and %T", n, rightSchema)
 	}
 
+	n, right = n.resolve(), right.resolve()
+	if n == right {
+		return n, nil
+	}
+
 	if n.KindValue != right.KindValue {
 		return nil, NewErrPosition(lastPos(n.Positions, right.Positions),
 			fmt.Errorf("can not merge two schema of different types [%s %s] and [%s %s]",
@@ -602,6 +659,23 @@ func mergeAlternates(left, right []Schema) ([]Schema, error) {
 	return result, nil
 }
 
+func equalOperations(left, right []Operation) bool {
+	if len(left) != len(right) {
+		return false
+	}
+	for i := range left {
+		if left[i].Op != right[i].Op {
+			return false
+		}
+		if v, err := Eq(left[i].Right, right[i].Right); err != nil {
+			return false
+		} else if b, err := ToBool(v); err != nil || !b {
+			return false
+		}
+	}
+	return true
+}
+
 func mergeConstraints(left, right []Constraint) ([]Constraint, error) {
 	if len(left) != len(right) {
 		return nil, fmt.Errorf("can not merge schemas with different constraints length %d != %d",
@@ -616,6 +690,10 @@ func mergeConstraints(left, right []Constraint) ([]Constraint, error) {
 				left.Op, right.Op)
 		}
 
+		if !equalOperations(left.Apply, right.Apply) {
+			return nil, fmt.Errorf("can not merge schemas with different constraints operations")
+		}
+
 		if left.Right == nil && right.Right == nil {
 			result = append(result, left)
 			continue
@@ -652,7 +730,11 @@ func mergeConstraints(left, right []Constraint) ([]Constraint, error) {
 }
 
 func (n *TypeSchema) Validate(ctx context.Context, right Value) (Value, error) {
-	return checkType(ctx, n, right)
+	if n.unresolved() {
+		return nil, NewErrPosition(lastPos(n.Positions, nil),
+			fmt.Errorf("schema %s can not be used before it is defined", n.Path))
+	}
+	return checkType(ctx, n.resolve(), right)
 }
 
 type Defaulter interface {
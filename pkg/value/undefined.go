@@ -30,6 +30,15 @@ func IsDefined(val Value) bool {
 type Undefined struct {
 	Err error
 	Pos Position
+	// Missing is set if the value is known to not exist, such as a key that is not in
+	// an object, as opposed to a value that is not evaluated yet
+	Missing bool
+}
+
+// IsMissing returns true if val is undefined because it is known to not exist
+func IsMissing(val Value) bool {
+	u, ok := val.(Undefined)
+	return ok && u.Missing
 }
 
 func (u Undefined) Eq(v Value) (Value, error) {
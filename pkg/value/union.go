@@ -0,0 +1,189 @@
+package value
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Discriminator is the tag field of a union of object schemas such as
+// {type: "http", url: string} || {type: "tcp", port: number}. Each alternate requires
+// a different constant value for the tag field.
+type Discriminator struct {
+	Key  string
+	Tags []string
+	// Alternates are the alternates of the union in the same order as Tags
+	Alternates []*TypeSchema
+}
+
+// Lookup returns the alternate for the tag
+func (d *Discriminator) Lookup(tag string) (*TypeSchema, bool) {
+	for i, t := range d.Tags {
+		if t == tag {
+			return d.Alternates[i], true
+		}
+	}
+	return nil, false
+}
+
+// unionAlternates returns the alternates with the alternates of nested unions, as in
+// (a || b) || c, flattened.
+func unionAlternates(alternates []Schema, resolve func(*TypeSchema) *TypeSchema) (result []*TypeSchema, ok bool) {
+	for _, alt := range alternates {
+		ts, ok := alt.(*TypeSchema)
+		if !ok {
+			return nil, false
+		}
+		ts = resolve(ts)
+		if ts.isUnion() {
+			nested, ok := unionAlternates(ts.Alternates, resolve)
+			if !ok {
+				return nil, false
+			}
+			result = append(result, nested...)
+		} else {
+			result = append(result, ts)
+		}
+	}
+	return result, true
+}
+
+// isUnion returns true if the schema only requires that the value matches one of the
+// alternates
+func (n *TypeSchema) isUnion() bool {
+	return len(n.Alternates) > 0 && n.Object == nil && n.Array == nil && n.DefaultValue == nil &&
+		len(n.Constraints) == 1 && n.Constraints[0].Op == MustMatchAlternateOp
+}
+
+// discriminator returns the discriminator of the schema if it is a union of object schemas
+// that all require the same field to be a different constant string. References are
+// resolved with resolve.
+func (n *TypeSchema) discriminator(resolve func(*TypeSchema) *TypeSchema) (*Discriminator, bool) {
+	if !n.isUnion() {
+		return nil, false
+	}
+	return newDiscriminator(n.Alternates, resolve)
+}
+
+// newDiscriminator returns the discriminator of alternates that are all object schemas
+// that require the same field to be a different constant string
+func newDiscriminator(schemas []Schema, resolve func(*TypeSchema) *TypeSchema) (*Discriminator, bool) {
+	alternates, ok := unionAlternates(schemas, resolve)
+	if !ok || len(alternates) < 2 {
+		return nil, false
+	}
+	for _, alt := range alternates {
+		if alt.Object == nil {
+			return nil, false
+		}
+	}
+
+	for _, field := range alternates[0].Object.Fields {
+		if field.Match || field.Optional {
+			continue
+		}
+		if d, ok := discriminatorForKey(field.Key, alternates, resolve); ok {
+			return d, true
+		}
+	}
+
+	return nil, false
+}
+
+func discriminatorForKey(key string, alternates []*TypeSchema, resolve func(*TypeSchema) *TypeSchema) (*Discriminator, bool) {
+	result := &Discriminator{
+		Key:        key,
+		Alternates: alternates,
+	}
+	for _, alt := range alternates {
+		tag, ok := alt.Object.tag(key, resolve)
+		if !ok {
+			return nil, false
+		}
+		if _, exists := result.Lookup(tag); exists {
+			return nil, false
+		}
+		result.Tags = append(result.Tags, tag)
+	}
+	return result, true
+}
+
+// tag returns the constant string that the required field key must be
+func (n *ObjectSchema) tag(key string, resolve func(*TypeSchema) *TypeSchema) (string, bool) {
+	for _, field := range n.Fields {
+		if field.Match || field.Optional || field.Key != key {
+			continue
+		}
+		ts, ok := field.Schema.(*TypeSchema)
+		if !ok {
+			return "", false
+		}
+		return ts.constantString(resolve)
+	}
+	return "", false
+}
+
+// constantString returns the string that a value must be equal to, as in the schemas
+// "http" or string == "http"
+func (n *TypeSchema) constantString(resolve func(*TypeSchema) *TypeSchema) (string, bool) {
+	n = resolve(n)
+	if n.KindValue != StringKind || len(n.Alternates) > 0 {
+		return "", false
+	}
+	for _, constraint := range n.Constraints {
+		if Operator(constraint.Op) == EqOp && len(constraint.Apply) == 0 {
+			if s, ok := constraint.Right.(String); ok {
+				return string(s), true
+			}
+		}
+	}
+	if s, ok := n.DefaultValue.(String); ok && len(n.Constraints) == 0 {
+		return string(s), true
+	}
+	return "", false
+}
+
+// validateDiscriminated validates right against the alternate selected by the value of the
+// discriminator key. ok is false if the schema is not a discriminated union or right
+// does not have the key, in which case every alternate must be tried.
+func (n *TypeSchema) validateDiscriminated(ctx context.Context, right Value) (_ Value, ok bool, _ error) {
+	d, ok := n.discriminator((*TypeSchema).resolve)
+	if !ok {
+		return nil, false, nil
+	}
+	return d.validate(ctx, right, n.Path)
+}
+
+// validate validates right against the alternate selected by the value of the key. ok
+// is false if right does not have the key.
+func (d *Discriminator) validate(ctx context.Context, right Value, schemaPath Path) (_ Value, ok bool, _ error) {
+	if right.Kind() != ObjectKind {
+		return nil, false, nil
+	}
+
+	tagValue, ok, err := Lookup(right, NewValue(d.Key))
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	tag, ok := tagValue.(String)
+	if !ok {
+		return nil, false, nil
+	}
+
+	alt, ok := d.Lookup(string(tag))
+	if !ok {
+		tags := make([]string, 0, len(d.Tags))
+		for _, t := range d.Tags {
+			tags = append(tags, fmt.Sprintf("%q", t))
+		}
+		return nil, true, &ErrSchemaViolation{
+			Key:        d.Key,
+			DataPath:   GetDataPath(WithDataKeyPath(ctx, d.Key)),
+			SchemaPath: schemaPath,
+			Err:        fmt.Errorf("unknown value %q, expected one of %s", tag, strings.Join(tags, ", ")),
+		}
+	}
+
+	v, err := alt.Validate(ctx, right)
+	return v, true, err
+}